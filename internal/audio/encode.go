@@ -0,0 +1,136 @@
+package audio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-audio/wav"
+)
+
+type Format string
+
+const (
+	FormatOpus Format = "opus"
+	FormatFLAC Format = "flac"
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case FormatOpus, FormatFLAC:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unsupported audio format %q, must be one of: opus, flac", s)
+	}
+}
+
+func (f Format) Ext() string {
+	return "." + string(f)
+}
+
+func (f Format) codecArgs() []string {
+	switch f {
+	case FormatFLAC:
+		return []string{"-c:a", "flac"}
+	default:
+		// speech at 16kHz mono stays intelligible at low bitrates
+		return []string{"-c:a", "libopus", "-b:a", "24k", "-application", "voip"}
+	}
+}
+
+// Duration returns the length of a WAV file.
+func Duration(path string) (time.Duration, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	dec := wav.NewDecoder(f)
+	if !dec.IsValidFile() {
+		return 0, fmt.Errorf("%s: invalid wav file", path)
+	}
+
+	return dec.Duration()
+}
+
+// Concat joins WAV files, in order, into a single compressed file.
+func Concat(ctx context.Context, inputs []string, output string, format Format) error {
+	if len(inputs) == 0 {
+		return errors.New("no audio to concatenate")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(output), 0700); err != nil {
+		return err
+	}
+
+	list, err := os.CreateTemp(filepath.Dir(inputs[0]), "concat-*.txt")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(list.Name())
+
+	for _, in := range inputs {
+		abs, err := filepath.Abs(in)
+		if err != nil {
+			_ = list.Close()
+			return err
+		}
+		// the concat demuxer uses single quotes, escaped as '\''
+		if _, err = fmt.Fprintf(list, "file '%s'\n", strings.ReplaceAll(abs, "'", `'\''`)); err != nil {
+			_ = list.Close()
+			return err
+		}
+	}
+
+	if err = list.Close(); err != nil {
+		return err
+	}
+
+	args := []string{"-f", "concat", "-safe", "0", "-i", list.Name()}
+	args = append(args, format.codecArgs()...)
+	args = append(args, "-y", output)
+
+	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("ffmpeg: %w: %s", err, lastLine(out))
+	}
+
+	return nil
+}
+
+// Extract decodes a section of a recording into a 16kHz mono WAV file, the input
+// format expected by the transcribers. A zero duration extracts until the end.
+func Extract(ctx context.Context, input, output string, offset, duration time.Duration) error {
+	var args []string
+	if offset > 0 {
+		args = append(args, "-ss", fmt.Sprintf("%0.3f", offset.Seconds()))
+	}
+	if duration > 0 {
+		args = append(args, "-t", fmt.Sprintf("%0.3f", duration.Seconds()))
+	}
+	args = append(args,
+		"-i", input,
+		"-ar", "16000",
+		"-ac", "1",
+		"-y",
+		output,
+	)
+
+	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("ffmpeg: %w: %s", err, lastLine(out))
+	}
+
+	return nil
+}
+
+func lastLine(out []byte) string {
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	return lines[len(lines)-1]
+}
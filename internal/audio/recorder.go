@@ -6,17 +6,20 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"os"
 	"os/exec"
 	"strings"
 	"time"
 )
 
 type Recorder struct {
-	storage []any
+	source string
 }
 
-func NewRecorder() *Recorder {
-	return &Recorder{}
+// NewRecorder creates a recorder for a PulseAudio source. An empty source records the
+// monitor of the first sink, i.e. the system audio.
+func NewRecorder(source string) *Recorder {
+	return &Recorder{source: source}
 }
 
 func (r *Recorder) Record(ctx context.Context, duration time.Duration, source, outputFile string) error {
@@ -28,6 +31,12 @@ func (r *Recorder) Record(ctx context.Context, duration time.Duration, source, o
 		"-y", // overwrite output file
 		outputFile,
 	)
+	// interrupt instead of killing ffmpeg, so it finalizes the file recorded so far
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(os.Interrupt)
+	}
+	cmd.WaitDelay = 5 * time.Second
+
 	if err := cmd.Run(); err != nil {
 		return err
 	}
@@ -36,6 +45,10 @@ func (r *Recorder) Record(ctx context.Context, duration time.Duration, source, o
 }
 
 func (r *Recorder) GetSource(ctx context.Context) (string, error) {
+	if r.source != "" {
+		return r.source, nil
+	}
+
 	pr, pw := io.Pipe()
 	defer pr.Close()
 
@@ -87,3 +100,40 @@ func (r *Recorder) GetSource(ctx context.Context) (string, error) {
 
 	return "", errors.New("no monitor sink found")
 }
+
+// Source is a PulseAudio source that can be recorded.
+type Source struct {
+	Name   string
+	Driver string
+	State  string
+	// Monitor sources capture what a sink plays, i.e. the system audio.
+	Monitor bool
+}
+
+// ListSources returns the PulseAudio sources, as reported by pactl.
+func (r *Recorder) ListSources(ctx context.Context) ([]Source, error) {
+	out, err := exec.CommandContext(ctx, "pactl", "list", "short", "sources").Output()
+	if err != nil {
+		return nil, fmt.Errorf("pactl: %w", err)
+	}
+
+	var sources []Source
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		// index, name, driver, sample spec, state
+		fields := strings.Split(line, "\t")
+		if len(fields) < 2 {
+			continue
+		}
+
+		src := Source{Name: fields[1], Monitor: strings.HasSuffix(fields[1], ".monitor")}
+		if len(fields) > 2 {
+			src.Driver = fields[2]
+		}
+		if len(fields) > 4 {
+			src.State = fields[4]
+		}
+		sources = append(sources, src)
+	}
+
+	return sources, nil
+}
@@ -0,0 +1,236 @@
+// Package cli implements the ekko command line.
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/tuanta7/ekko/internal/audio"
+	"github.com/tuanta7/ekko/internal/config"
+	"github.com/tuanta7/ekko/internal/core"
+	"github.com/tuanta7/ekko/internal/store"
+	"github.com/tuanta7/ekko/internal/transcriber"
+)
+
+// Exit codes, documented in the README.
+const (
+	ExitOK          = 0
+	ExitFailure     = 1
+	ExitUsage       = 2
+	ExitConfig      = 3
+	ExitNotFound    = 4
+	ExitInterrupted = 130
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) int
+}
+
+func commands() []command {
+	return []command{
+		{"record", "record and transcribe without the TUI", record},
+		{"transcribe", "transcribe an audio file", transcribe},
+		{"retranscribe", "transcribe the kept audio of a session again", retranscribe},
+		{"serve", "control sessions over a local HTTP API", serve},
+		{"sessions", "list, show, export and summarize saved sessions", sessions},
+		{"search", "search the saved transcripts", searchCmd},
+		{"devices", "list the audio sources that can be recorded", devices},
+		{"models", "list the available transcription models", models},
+	}
+}
+
+// Run executes the command named by the first argument, or the TUI when there is none,
+// and returns the exit code.
+func Run(args []string) int {
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name := args[0]
+		if name == "help" {
+			usage(os.Stdout)
+			return ExitOK
+		}
+
+		for _, cmd := range commands() {
+			if cmd.name == name {
+				return cmd.run(args[1:])
+			}
+		}
+
+		fmt.Fprintf(os.Stderr, "ekko: unknown command %q\n\n", name)
+		usage(os.Stderr)
+		return ExitUsage
+	}
+
+	return tui(args)
+}
+
+func usage(w io.Writer) {
+	_, _ = fmt.Fprintln(w, "Usage: ekko [flags]            start the terminal UI")
+	_, _ = fmt.Fprintln(w, "       ekko <command> [flags]")
+	_, _ = fmt.Fprintln(w, "\nCommands:")
+	for _, cmd := range commands() {
+		_, _ = fmt.Fprintf(w, "  %-13s %s\n", cmd.name, cmd.summary)
+	}
+	_, _ = fmt.Fprintln(w, "\nRun 'ekko <command> -h' for the flags of a command.")
+}
+
+// newFlagSet creates the flag set of a command. The usage line describes the positional arguments.
+func newFlagSet(name, usageLine string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(fs.Output(), "Usage: ekko %s\n\nFlags:\n", usageLine)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parse parses the flags, returning ok false and the exit code when the command must not run.
+func parse(fs *flag.FlagSet, args []string) (int, bool) {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return ExitOK, false
+		}
+		return ExitUsage, false
+	}
+	return ExitOK, true
+}
+
+func loadConfig(flags *config.Flags) (*config.Config, int) {
+	cfg, err := flags.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration: %v\n", err)
+		return nil, ExitConfig
+	}
+	return cfg, ExitOK
+}
+
+// newApplication creates the application with its transcriber client and session
+// store, the caller must call done once finished with it.
+func newApplication(ctx context.Context, cfg *config.Config) (app *core.Application, done func(), code int) {
+	redactor, err := cfg.Redactor()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration: %v\n", err)
+		return nil, nil, ExitConfig
+	}
+
+	st, created, code := openStore(cfg)
+	if st == nil {
+		return nil, nil, code
+	}
+
+	client, err := transcriber.NewClient(ctx, cfg.Transcriber.Mode, cfg.TranscriberOptions())
+	if err != nil {
+		_ = st.Close()
+		fmt.Fprintf(os.Stderr, "Failed to create transcriber client: %v\n", err)
+		return nil, nil, ExitFailure
+	}
+
+	coreCfg := cfg.Core()
+	coreCfg.Summarizer = newSummarizer(ctx, cfg)
+	coreCfg.Formatter = newFormatter(ctx, cfg)
+	if redactor != nil {
+		coreCfg.Redactor = redactor
+	}
+	coreCfg.Key = st.Key()
+
+	recorder := audio.NewRecorder(cfg.Recording.Source)
+	app = core.NewApplication(recorder, client, st, coreCfg)
+	sealAudio(app)
+	if created {
+		importOutputDir(app, cfg)
+	}
+
+	return app, func() {
+		_ = client.Close()
+		_ = st.Close()
+	}, ExitOK
+}
+
+// newStorageApplication creates an application for the commands that only work on saved
+// sessions, it can neither record nor transcribe. The caller must call done once
+// finished with it.
+func newStorageApplication(cfg *config.Config) (app *core.Application, done func(), code int) {
+	redactor, err := cfg.Redactor()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration: %v\n", err)
+		return nil, nil, ExitConfig
+	}
+
+	st, created, code := openStore(cfg)
+	if st == nil {
+		return nil, nil, code
+	}
+
+	coreCfg := cfg.Core()
+	coreCfg.Summarizer = newSummarizer(context.Background(), cfg)
+	if redactor != nil {
+		coreCfg.Redactor = redactor
+	}
+	coreCfg.Key = st.Key()
+
+	app = core.NewApplication(nil, nil, st, coreCfg)
+	sealAudio(app)
+	if created {
+		importOutputDir(app, cfg)
+	}
+
+	return app, func() { _ = st.Close() }, ExitOK
+}
+
+// openStore opens the session database, unlocked when encrypted, created reports
+// whether it did not exist yet.
+func openStore(cfg *config.Config) (st *store.SQLite, created bool, code int) {
+	_, err := os.Stat(cfg.Database())
+	created = errors.Is(err, os.ErrNotExist)
+
+	st, err = store.Open(cfg.Database())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open the session database: %v\n", err)
+		return nil, false, ExitFailure
+	}
+
+	if err = unlockStore(st, cfg); err != nil {
+		_ = st.Close()
+		fmt.Fprintf(os.Stderr, "Failed to unlock the session database: %v\n", err)
+		return nil, false, ExitFailure
+	}
+
+	if st.Encrypted() && len(cfg.Output.ExportFormats) > 0 {
+		_ = st.Close()
+		fmt.Fprintln(os.Stderr, "Invalid configuration: the sessions are encrypted, the automatic exports would be written in plaintext, unset output.export_formats")
+		return nil, false, ExitConfig
+	}
+
+	return st, created, ExitOK
+}
+
+// reportSkipped lists the JSON files of an imported directory that are not sessions.
+func reportSkipped(files []string) {
+	for _, f := range files {
+		fmt.Fprintf(os.Stderr, "Skipped %s, not a session\n", f)
+	}
+}
+
+// importOutputDir imports the sessions saved as JSON files in the output directory by
+// earlier versions, once, when the database is created.
+func importOutputDir(app *core.Application, cfg *config.Config) {
+	dir := cfg.Core().OutputDir
+	if _, err := os.Stat(dir); err != nil {
+		return // nothing was saved yet
+	}
+
+	n, skipped, err := app.ImportSessions(dir)
+	if n > 0 {
+		fmt.Fprintf(os.Stderr, "Imported %d sessions from %s\n", n, dir)
+	}
+	reportSkipped(skipped)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to import sessions, retry with 'ekko sessions import': %v\n", err)
+	}
+}
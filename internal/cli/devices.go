@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/tuanta7/ekko/internal/audio"
+	"github.com/tuanta7/ekko/internal/config"
+)
+
+func devices(args []string) int {
+	fs := newFlagSet("devices", "devices [flags]")
+	flags := config.RegisterFlags(fs)
+	if code, ok := parse(fs, args); !ok {
+		return code
+	}
+
+	cfg, code := loadConfig(flags)
+	if cfg == nil {
+		return code
+	}
+
+	ctx := context.Background()
+	recorder := audio.NewRecorder(cfg.Recording.Source)
+
+	sources, err := recorder.ListSources(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to list audio sources: %v\n", err)
+		return ExitFailure
+	}
+
+	// the source a session would record, configured or detected
+	selected, _ := recorder.GetSource(ctx)
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "\tNAME\tTYPE\tSTATE")
+	for _, src := range sources {
+		kind := "input"
+		if src.Monitor {
+			kind = "monitor"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", marker(src.Name == selected), src.Name, kind, orDash(src.State))
+	}
+	_ = tw.Flush()
+
+	return ExitOK
+}
@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/tuanta7/ekko/internal/config"
+	"github.com/tuanta7/ekko/internal/core"
+	"github.com/tuanta7/ekko/internal/store"
+)
+
+// unlockStore unlocks the encrypted sessions, or encrypts them when the configuration
+// asks for it, with the configured passphrase or the one typed on the terminal.
+func unlockStore(st *store.SQLite, cfg *config.Config) error {
+	if !st.Encrypted() && !cfg.Storage.Encrypt {
+		return nil
+	}
+
+	pass, err := passphrase(cfg, !st.Encrypted())
+	if err != nil {
+		return err
+	}
+
+	if st.Encrypted() {
+		return st.Unlock(pass)
+	}
+
+	fmt.Fprintln(os.Stderr, "Encrypting the saved sessions...")
+	if err = st.Encrypt(pass); err != nil {
+		return err
+	}
+
+	warnPlaintext(cfg)
+	return nil
+}
+
+// warnPlaintext lists the exports and the transcripts saved as JSON files by earlier
+// versions, left in plaintext in the output directory once the sessions are encrypted.
+func warnPlaintext(cfg *config.Config) {
+	dir := cfg.Core().OutputDir
+	exports, _ := filepath.Glob(filepath.Join(dir, "exports", "*"))
+	imported, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+
+	files := append(exports, imported...)
+	if len(files) == 0 {
+		return
+	}
+
+	fmt.Fprintln(os.Stderr, "These transcripts are left in plaintext, delete them once they are no longer needed:")
+	for _, f := range files {
+		fmt.Fprintf(os.Stderr, "  %s\n", f)
+	}
+}
+
+// passphrase returns the configured passphrase, or asks for it on the terminal. A new
+// passphrase is asked twice.
+func passphrase(cfg *config.Config, confirm bool) (string, error) {
+	if cfg.Storage.Passphrase != "" {
+		return cfg.Storage.Passphrase, nil
+	}
+
+	if !term.IsTerminal(os.Stdin.Fd()) {
+		return "", errors.New("a passphrase is needed, set EKKO_PASSPHRASE or run ekko in a terminal")
+	}
+
+	pass, err := readPassphrase("Passphrase: ")
+	if err != nil || !confirm {
+		return pass, err
+	}
+
+	again, err := readPassphrase("Repeat the passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if again != pass {
+		return "", errors.New("the passphrases do not match")
+	}
+	return pass, nil
+}
+
+func readPassphrase(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	pass, err := term.ReadPassword(os.Stdin.Fd())
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the passphrase: %w", err)
+	}
+	if len(pass) == 0 {
+		return "", errors.New("empty passphrase")
+	}
+	return string(pass), nil
+}
+
+// sealAudio seals the recordings retained before the encryption was enabled.
+func sealAudio(app *core.Application) {
+	n, err := app.SealAudio()
+	if n > 0 {
+		fmt.Fprintf(os.Stderr, "Encrypted %d retained recordings\n", n)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to encrypt the retained audio: %v\n", err)
+	}
+}
@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/tuanta7/ekko/internal/config"
+	"github.com/tuanta7/ekko/internal/core"
+	"github.com/tuanta7/ekko/internal/format"
+	"github.com/tuanta7/ekko/internal/summary"
+)
+
+// newFormatter creates the formatter of the configured mode, nil when the transcript is
+// kept raw. A language model that cannot be created is reported and the rules are used
+// instead.
+func newFormatter(ctx context.Context, cfg *config.Config) core.Formatter {
+	language := cfg.Transcriber.Language
+	switch cfg.Formatting.Mode {
+	case format.RulesMode:
+		return format.NewRules(language)
+	case format.LLMMode:
+		provider, opts := cfg.SummaryOptions()
+		model, err := summary.New(ctx, provider, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Formatting with the rules, failed to create the language model client: %v\n", err)
+			return format.NewRules(language)
+		}
+		return format.NewLLM(model, language)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/tuanta7/ekko/internal/config"
+	"github.com/tuanta7/ekko/internal/transcriber"
+)
+
+func models(args []string) int {
+	fs := newFlagSet("models", "models [flags]")
+	flags := config.RegisterFlags(fs)
+	if code, ok := parse(fs, args); !ok {
+		return code
+	}
+
+	cfg, code := loadConfig(flags)
+	if cfg == nil {
+		return code
+	}
+
+	configured := cfg.TranscriberOptions().Model
+	if configured == "" {
+		configured = transcriber.DefaultWhisperModel
+		if cfg.Transcriber.Mode == transcriber.GeminiMode {
+			configured = transcriber.DefaultGeminiModel
+		}
+	}
+
+	// whisper models are looked up next to the configured one
+	whisperDir := filepath.Dir(transcriber.DefaultWhisperModel)
+	if cfg.Transcriber.Mode == transcriber.WhisperMode {
+		whisperDir = filepath.Dir(configured)
+	}
+
+	local, err := filepath.Glob(filepath.Join(whisperDir, "ggml-*.bin"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to list whisper models: %v\n", err)
+		return ExitFailure
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "\tBACKEND\tMODEL")
+	for _, m := range local {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", marker(m == configured), transcriber.WhisperMode, m)
+	}
+	for _, m := range transcriber.GeminiModels {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", marker(m == configured), transcriber.GeminiMode, m)
+	}
+	_ = tw.Flush()
+
+	if len(local) == 0 {
+		fmt.Fprintf(os.Stderr, "\nNo whisper models found in %s, see models/README.md to download one.\n", whisperDir)
+	}
+
+	return ExitOK
+}
+
+func marker(selected bool) string {
+	if selected {
+		return "*"
+	}
+	return ""
+}
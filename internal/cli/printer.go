@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/tuanta7/ekko/internal/core"
+	"github.com/tuanta7/ekko/internal/session"
+)
+
+// eventPrinter writes the transcript of a headless session for other programs.
+type eventPrinter struct {
+	w      io.Writer
+	format string
+}
+
+func newEventPrinter(w io.Writer, format string) (*eventPrinter, error) {
+	switch format {
+	case "text", "jsonl", "none":
+		return &eventPrinter{w: w, format: format}, nil
+	default:
+		return nil, fmt.Errorf("unsupported format %q, must be one of: text, jsonl, none", format)
+	}
+}
+
+// savedJSON is the last JSON line, once the session is saved.
+type savedJSON struct {
+	Type    string    `json:"type"`
+	Time    time.Time `json:"time"`
+	Session string    `json:"session"`
+}
+
+// summaryJSON follows the saved line once the session is summarized.
+type summaryJSON struct {
+	Type    string           `json:"type"`
+	Time    time.Time        `json:"time"`
+	Session string           `json:"session"`
+	Summary *session.Summary `json:"summary"`
+}
+
+func (p *eventPrinter) print(ev core.Event) error {
+	switch p.format {
+	case "text":
+		if ev.Type != core.EventChunk {
+			return nil
+		}
+		text := strings.TrimSpace(ev.Text)
+		if text == "" {
+			return nil
+		}
+		_, err := fmt.Fprintln(p.w, text)
+		return err
+	case "jsonl":
+		return p.writeJSON(ev)
+	default:
+		return nil
+	}
+}
+
+// saved reports the ID of the saved session, only in the jsonl format.
+func (p *eventPrinter) saved(id string) error {
+	if p.format != "jsonl" {
+		return nil
+	}
+	return p.writeJSON(savedJSON{Type: "saved", Time: time.Now(), Session: id})
+}
+
+// summary reports the summary of the saved session, only in the jsonl format.
+func (p *eventPrinter) summary(id string, sum *session.Summary) error {
+	if p.format != "jsonl" {
+		return nil
+	}
+	return p.writeJSON(summaryJSON{Type: "summary", Time: time.Now(), Session: id, Summary: sum})
+}
+
+func (p *eventPrinter) writeJSON(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = p.w.Write(append(data, '\n'))
+	return err
+}
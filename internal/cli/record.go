@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/tuanta7/ekko/internal/config"
+	"github.com/tuanta7/ekko/internal/core"
+	"github.com/tuanta7/ekko/internal/session"
+)
+
+// record runs a session without the TUI. Finalized chunks are streamed to stdout, as
+// plain text or JSON lines, status and errors go to stderr. The session is saved when
+// the duration elapses or on SIGINT/SIGTERM.
+func record(args []string) int {
+	fs := newFlagSet("record", "record [flags]")
+	flags := config.RegisterFlags(fs)
+	duration := fs.Duration("duration", 0, "how long to record, e.g. 30m, by default until interrupted")
+	format := fs.String("format", "text", "stdout format: text (one line per chunk), jsonl (every event), none")
+	opts := registerSessionFlags(fs)
+	if code, ok := parse(fs, args); !ok {
+		return code
+	}
+
+	printer, err := newEventPrinter(os.Stdout, *format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ekko record: %v\n", err)
+		return ExitUsage
+	}
+
+	cfg, code := loadConfig(flags)
+	if cfg == nil {
+		return code
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	app, done, code := newApplication(ctx, cfg)
+	if app == nil {
+		return code
+	}
+	defer done()
+
+	stream, err := app.Start(opts.sessionOptions(cfg))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to start session: %v\n", err)
+		return ExitFailure
+	}
+
+	var timeout <-chan time.Time
+	if *duration > 0 {
+		timer := time.NewTimer(*duration)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	// the session is stopped in the background, the stream is read until it is closed
+	// so the chunks transcribed while stopping are printed as well
+	var result chan stopResult
+	interrupted := ctx.Done()
+	stop := func() {
+		if result != nil {
+			return
+		}
+		interrupted = nil
+		// restore the default signal handling, a second interrupt kills the process and
+		// the journal keeps the session recoverable
+		cancel()
+		result = make(chan stopResult, 1)
+		go func() {
+			id, err := app.Stop()
+			result <- stopResult{id, err}
+		}()
+	}
+
+	printing := true
+events:
+	for {
+		select {
+		case ev, ok := <-stream:
+			if !ok {
+				break events
+			}
+			logEvent(ev)
+			if !printing {
+				continue
+			}
+			if err := printer.print(ev); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to write transcript: %v\n", err)
+				printing = false
+				stop()
+			}
+		case <-timeout:
+			stop()
+		case <-interrupted:
+			fmt.Fprintln(os.Stderr, "Stopping, press Ctrl+C again to quit without saving")
+			stop()
+		}
+	}
+
+	// the session may have ended on its own, e.g. the source disappeared
+	stop()
+	res := <-result
+	if res.id == "" {
+		fmt.Fprintf(os.Stderr, "Failed to save session: %v\n", res.err)
+		return ExitFailure
+	}
+
+	fmt.Fprintf(os.Stderr, "Saved session %s\n", res.id)
+	if res.err != nil {
+		// the session is saved, only the journal removal, the exports or the indexing failed
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", res.err)
+	}
+	if printing {
+		_ = printer.saved(res.id)
+	}
+	summarize(app, res.id, os.Stderr, printer)
+	return ExitOK
+}
+
+type stopResult struct {
+	id  string
+	err error
+}
+
+// logEvent reports the status and errors of a headless session on stderr.
+func logEvent(ev core.Event) {
+	switch ev.Type {
+	case core.EventStatus:
+		fmt.Fprintln(os.Stderr, ev.Text)
+	case core.EventError:
+		fmt.Fprintf(os.Stderr, "Error: %v\n", ev.Err)
+	}
+}
+
+type sessionFlags struct {
+	out   *string
+	title *string
+	tags  *string
+}
+
+func registerSessionFlags(fs *flag.FlagSet) *sessionFlags {
+	return &sessionFlags{
+		out:   fs.String("out", "", "also export the transcript to this file, in the format of its extension (.json, .md, .txt)"),
+		title: fs.String("title", "", "session title"),
+		tags:  fs.String("tags", "", "comma separated session tags"),
+	}
+}
+
+func (f *sessionFlags) sessionOptions(cfg *config.Config) core.SessionOptions {
+	return core.SessionOptions{
+		ChunkDuration: cfg.Recording.ChunkDuration,
+		Title:         *f.title,
+		Tags:          session.ParseTags(*f.tags),
+		OutputPath:    *f.out,
+	}
+}
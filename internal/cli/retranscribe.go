@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/tuanta7/ekko/internal/config"
+)
+
+// retranscribe runs the retained audio of a saved session through another backend,
+// e.g. a large whisper model, and saves the output as a new revision.
+func retranscribe(args []string) int {
+	fs := newFlagSet("retranscribe", "retranscribe [flags] <session>")
+	flags := config.RegisterFlags(fs)
+	if code, ok := parse(fs, args); !ok {
+		return code
+	}
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return ExitUsage
+	}
+
+	cfg, code := loadConfig(flags)
+	if cfg == nil {
+		return code
+	}
+
+	ctx := context.Background()
+	app, done, code := newApplication(ctx, cfg)
+	if app == nil {
+		return code
+	}
+	defer done()
+
+	id, code := findSession(app, fs.Arg(0))
+	if id == "" {
+		return code
+	}
+
+	revision, err := app.Retranscribe(ctx, id, func(done, total int) {
+		fmt.Fprintf(os.Stderr, "\rTranscribed %d/%d chunks", done, total)
+	})
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to retranscribe session: %v\n", err)
+		return ExitFailure
+	}
+
+	fmt.Println(revision)
+	return ExitOK
+}
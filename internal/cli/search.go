@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/tuanta7/ekko/internal/config"
+	"github.com/tuanta7/ekko/internal/search"
+	"github.com/tuanta7/ekko/internal/session"
+)
+
+func searchCmd(args []string) int {
+	fs := newFlagSet("search", "search [flags] <words...>")
+	flags := config.RegisterFlags(fs)
+	limit := fs.Int("limit", 20, "maximum number of results")
+	reindex := fs.Bool("reindex", false, "rebuild the index from the saved transcripts first")
+	if code, ok := parse(fs, args); !ok {
+		return code
+	}
+
+	query := strings.Join(fs.Args(), " ")
+	if query == "" && !*reindex {
+		fs.Usage()
+		return ExitUsage
+	}
+
+	cfg, code := loadConfig(flags)
+	if cfg == nil {
+		return code
+	}
+
+	// the search index does not need a transcriber
+	app, done, code := newStorageApplication(cfg)
+	if app == nil {
+		return code
+	}
+	defer done()
+
+	if *reindex {
+		n, err := app.Reindex()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to rebuild the index: %v\n", err)
+			return ExitFailure
+		}
+		fmt.Fprintf(os.Stderr, "Indexed %d transcripts\n", n)
+		if query == "" {
+			return ExitOK
+		}
+	}
+
+	results, err := app.Search(query, *limit)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Search failed: %v\n", err)
+		if errors.Is(err, search.ErrEmptyQuery) {
+			return ExitUsage
+		}
+		return ExitFailure
+	}
+
+	if len(results) == 0 {
+		fmt.Fprintln(os.Stderr, "No matches")
+		return ExitNotFound
+	}
+
+	for _, r := range results {
+		fmt.Printf("%s  %s  [%s]  %s\n",
+			r.ID,
+			r.StartedAt.Format("2006-01-02 15:04"),
+			session.FormatOffset(r.Offset),
+			orDash(r.Title))
+		fmt.Printf("    %s\n", search.Snippet(r.Text, r.Terms, 100))
+	}
+
+	return ExitOK
+}
@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/tuanta7/ekko/internal/config"
+	"github.com/tuanta7/ekko/internal/core"
+	"github.com/tuanta7/ekko/internal/server"
+)
+
+// serve runs the local API until SIGINT or SIGTERM, a session still running is saved.
+func serve(args []string) int {
+	fs := newFlagSet("serve", "serve [flags]")
+	flags := config.RegisterFlags(fs)
+	if code, ok := parse(fs, args); !ok {
+		return code
+	}
+
+	cfg, code := loadConfig(flags)
+	if cfg == nil {
+		return code
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	app, done, code := newApplication(ctx, cfg)
+	if app == nil {
+		return code
+	}
+	defer done()
+
+	addr := cfg.Server.Listen
+	if addr == "" {
+		addr = server.DefaultListen
+	}
+
+	ln, err := server.Listen(addr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to listen on %s: %v\n", addr, err)
+		return ExitFailure
+	}
+
+	logger := log.New(os.Stderr, "", log.LstdFlags)
+	srv := server.New(app, server.Config{
+		Listen:           addr,
+		FilenameTemplate: cfg.Core().FilenameTemplate,
+		ChunkDuration:    cfg.Recording.ChunkDuration,
+		Logger:           logger,
+	})
+
+	logger.Printf("Listening on %s", addr)
+	if err = srv.Serve(ctx, ln); err != nil {
+		logger.Printf("Server failed: %v", err)
+	}
+	cancel() // a second signal kills the process, the journal keeps the session recoverable
+
+	id, stopErr := app.Stop()
+	switch {
+	case errors.Is(stopErr, core.ErrNoSession):
+	case id == "":
+		logger.Printf("Failed to save session: %v", stopErr)
+		return ExitFailure
+	default:
+		logger.Printf("Saved session %s", id)
+		if stopErr != nil {
+			logger.Printf("Warning: %v", stopErr)
+		}
+	}
+
+	if err != nil {
+		return ExitFailure
+	}
+	return ExitOK
+}
@@ -0,0 +1,280 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/tuanta7/ekko/internal/config"
+	"github.com/tuanta7/ekko/internal/core"
+	"github.com/tuanta7/ekko/internal/export"
+	"github.com/tuanta7/ekko/internal/session"
+)
+
+func sessions(args []string) int {
+	subcommands := map[string]func([]string) int{
+		"list":      sessionsList,
+		"show":      sessionsShow,
+		"export":    sessionsExport,
+		"import":    sessionsImport,
+		"summarize": sessionsSummarize,
+	}
+
+	if len(args) > 0 {
+		if run, ok := subcommands[args[0]]; ok {
+			return run(args[1:])
+		}
+	}
+
+	_, _ = fmt.Fprintln(os.Stderr, "Usage: ekko sessions list|show|export|import|summarize [flags]")
+	return ExitUsage
+}
+
+func sessionsList(args []string) int {
+	fs := newFlagSet("sessions list", "sessions list [flags]")
+	flags := config.RegisterFlags(fs)
+	if code, ok := parse(fs, args); !ok {
+		return code
+	}
+
+	cfg, code := loadConfig(flags)
+	if cfg == nil {
+		return code
+	}
+
+	app, done, code := newStorageApplication(cfg)
+	if app == nil {
+		return code
+	}
+	defer done()
+
+	entries, err := app.ListSessions()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to list sessions: %v\n", err)
+		return ExitFailure
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "ID\tSTARTED\tDURATION\tTITLE\tBACKEND\tCHUNKS")
+	for _, e := range entries {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%d\n",
+			e.Manifest.ID,
+			e.Manifest.StartedAt.Format("2006-01-02 15:04"),
+			e.Manifest.Duration().Round(time.Second),
+			orDash(e.Manifest.Title),
+			orDash(e.Manifest.Backend),
+			e.Chunks)
+	}
+	_ = tw.Flush()
+
+	return ExitOK
+}
+
+func sessionsShow(args []string) int {
+	fs := newFlagSet("sessions show", "sessions show [flags] <session>")
+	flags := config.RegisterFlags(fs)
+	raw := fs.Bool("raw", false, "print the text as transcribed, before it was formatted")
+	if code, ok := parse(fs, args); !ok {
+		return code
+	}
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return ExitUsage
+	}
+
+	cfg, code := loadConfig(flags)
+	if cfg == nil {
+		return code
+	}
+
+	app, done, code := newStorageApplication(cfg)
+	if app == nil {
+		return code
+	}
+	defer done()
+
+	s, code := loadSession(app, fs.Arg(0))
+	if s == nil {
+		return code
+	}
+
+	m := s.Manifest
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "ID:\t%s\n", m.ID)
+	_, _ = fmt.Fprintf(tw, "Title:\t%s\n", orDash(m.Title))
+	_, _ = fmt.Fprintf(tw, "Tags:\t%s\n", orDash(strings.Join(m.Tags, ", ")))
+	_, _ = fmt.Fprintf(tw, "Started:\t%s\n", m.StartedAt.Format(time.RFC1123))
+	_, _ = fmt.Fprintf(tw, "Duration:\t%s\n", m.Duration().Round(time.Second))
+	_, _ = fmt.Fprintf(tw, "Backend:\t%s (%s)\n", orDash(m.Backend), orDash(m.Model))
+	_, _ = fmt.Fprintf(tw, "Revision:\t%d\n", m.RevisionNumber())
+	_ = tw.Flush()
+	fmt.Println()
+
+	if s.Summary != nil {
+		printSummary(os.Stdout, s.Summary)
+		fmt.Println()
+	}
+
+	for _, item := range s.Timeline() {
+		switch {
+		case item.Pause != nil:
+			printPause(*item.Pause)
+		case item.Bookmark != nil:
+			printBookmark(*item.Bookmark)
+		case item.Chunk.Error != "":
+			fmt.Printf("[%s] (error: %s)\n", session.FormatOffset(item.Offset()), item.Chunk.Error)
+		case *raw:
+			fmt.Printf("[%s] %s\n", session.FormatOffset(item.Offset()), strings.TrimSpace(item.Chunk.Raw()))
+		default:
+			if item.Chunk.Paragraph && item.Chunk != &s.Chunks[0] {
+				fmt.Println()
+			}
+			fmt.Printf("[%s] %s\n", session.FormatOffset(item.Offset()), strings.TrimSpace(item.Chunk.Text))
+		}
+	}
+
+	return ExitOK
+}
+
+func sessionsExport(args []string) int {
+	fs := newFlagSet("sessions export", "sessions export [flags] <session>")
+	flags := config.RegisterFlags(fs)
+	format := fs.String("format", "json", "export format: "+strings.Join(export.Formats(), ", "))
+	out := fs.String("out", "", "output file, defaults to stdout")
+	if code, ok := parse(fs, args); !ok {
+		return code
+	}
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return ExitUsage
+	}
+
+	cfg, code := loadConfig(flags)
+	if cfg == nil {
+		return code
+	}
+
+	exporter, err := export.Get(*format, cfg.ExportOptions())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return ExitUsage
+	}
+
+	app, done, code := newStorageApplication(cfg)
+	if app == nil {
+		return code
+	}
+	defer done()
+
+	s, code := loadSession(app, fs.Arg(0))
+	if s == nil {
+		return code
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.OpenFile(*out, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create %s: %v\n", *out, err)
+			return ExitFailure
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err = exporter.Export(w, s); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to export session: %v\n", err)
+		return ExitFailure
+	}
+
+	return ExitOK
+}
+
+// sessionsImport imports sessions saved as JSON files, by earlier versions or exported
+// from another machine. The output directory is imported when no path is given.
+func sessionsImport(args []string) int {
+	fs := newFlagSet("sessions import", "sessions import [flags] [file or directory...]")
+	flags := config.RegisterFlags(fs)
+	if code, ok := parse(fs, args); !ok {
+		return code
+	}
+
+	cfg, code := loadConfig(flags)
+	if cfg == nil {
+		return code
+	}
+
+	app, done, code := newStorageApplication(cfg)
+	if app == nil {
+		return code
+	}
+	defer done()
+
+	paths := fs.Args()
+	if len(paths) == 0 {
+		paths = []string{cfg.Core().OutputDir}
+	}
+
+	n, skipped, err := app.ImportSessions(paths...)
+	reportSkipped(skipped)
+	fmt.Fprintf(os.Stderr, "Imported %d sessions\n", n)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to import some sessions: %v\n", err)
+		return ExitFailure
+	}
+
+	return ExitOK
+}
+
+// findSession resolves a session ID or unique ID prefix.
+func findSession(app *core.Application, ref string) (string, int) {
+	id, err := app.FindSession(ref)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		if errors.Is(err, session.ErrNotFound) {
+			return "", ExitNotFound
+		}
+		return "", ExitFailure
+	}
+	return id, ExitOK
+}
+
+func loadSession(app *core.Application, ref string) (*session.Session, int) {
+	id, code := findSession(app, ref)
+	if id == "" {
+		return nil, code
+	}
+
+	s, err := app.LoadSession(id)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load session: %v\n", err)
+		return nil, ExitFailure
+	}
+
+	return s, ExitOK
+}
+
+func printPause(p session.Pause) {
+	fmt.Printf("[%s] (paused for %s)\n", session.FormatOffset(p.Start()), p.Duration().Round(time.Second))
+}
+
+func printBookmark(b session.Bookmark) {
+	if b.Note == "" {
+		fmt.Printf("[%s] (bookmark)\n", session.FormatOffset(b.Offset()))
+		return
+	}
+	fmt.Printf("[%s] (bookmark: %s)\n", session.FormatOffset(b.Offset()), b.Note)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
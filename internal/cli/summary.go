@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/tuanta7/ekko/internal/config"
+	"github.com/tuanta7/ekko/internal/core"
+	"github.com/tuanta7/ekko/internal/session"
+	"github.com/tuanta7/ekko/internal/summary"
+)
+
+// newSummarizer creates the summarizer of the configured provider, nil when the
+// summaries are disabled. A provider that cannot be created is reported and disabled,
+// the sessions can still be recorded and browsed.
+func newSummarizer(ctx context.Context, cfg *config.Config) core.Summarizer {
+	provider, opts := cfg.SummaryOptions()
+	if provider == "" {
+		return nil
+	}
+
+	s, err := summary.New(ctx, provider, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Summaries disabled, failed to create the summary client: %v\n", err)
+		return nil
+	}
+	return s
+}
+
+// summarize writes the summary of a session that was just saved, when a provider is
+// configured. It is printed to w, or as a JSON line when the printer writes JSON lines.
+func summarize(app *core.Application, id string, w io.Writer, printer *eventPrinter) {
+	if !app.CanSummarize() {
+		return
+	}
+
+	fmt.Fprintln(os.Stderr, "Summarizing the session…")
+	sum, err := app.Summarize(context.Background(), id)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to summarize session, retry with 'ekko sessions summarize %s': %v\n", id, err)
+		return
+	}
+
+	if printer != nil && printer.format == "jsonl" {
+		_ = printer.summary(id, sum)
+		return
+	}
+	printSummary(w, sum)
+}
+
+// sessionsSummarize writes the summary of a saved session again, e.g. with another model.
+func sessionsSummarize(args []string) int {
+	fs := newFlagSet("sessions summarize", "sessions summarize [flags] <session>")
+	flags := config.RegisterFlags(fs)
+	if code, ok := parse(fs, args); !ok {
+		return code
+	}
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return ExitUsage
+	}
+
+	cfg, code := loadConfig(flags)
+	if cfg == nil {
+		return code
+	}
+
+	app, done, code := newStorageApplication(cfg)
+	if app == nil {
+		return code
+	}
+	defer done()
+
+	if !app.CanSummarize() {
+		fmt.Fprintf(os.Stderr, "%v, set summary.provider or -summary\n", core.ErrNoSummarizer)
+		return ExitConfig
+	}
+
+	id, code := findSession(app, fs.Arg(0))
+	if id == "" {
+		return code
+	}
+
+	sum, err := app.Summarize(context.Background(), id)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to summarize session: %v\n", err)
+		return ExitFailure
+	}
+
+	printSummary(os.Stdout, sum)
+	return ExitOK
+}
+
+func printSummary(w io.Writer, sum *session.Summary) {
+	_, _ = fmt.Fprintf(w, "Summary:\n  %s\n", sum.Text)
+	if len(sum.Decisions) > 0 {
+		_, _ = fmt.Fprintln(w, "\nDecisions:")
+		for _, d := range sum.Decisions {
+			_, _ = fmt.Fprintf(w, "  - %s\n", d)
+		}
+	}
+	if len(sum.ActionItems) > 0 {
+		_, _ = fmt.Fprintln(w, "\nAction items:")
+		for _, item := range sum.ActionItems {
+			_, _ = fmt.Fprintf(w, "  - %s (%s)\n", item.Task, orDash(item.Owner))
+		}
+	}
+}
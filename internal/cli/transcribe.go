@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/tuanta7/ekko/internal/config"
+)
+
+func transcribe(args []string) int {
+	fs := newFlagSet("transcribe", "transcribe [flags] <audio file>")
+	flags := config.RegisterFlags(fs)
+	opts := registerSessionFlags(fs)
+	save := fs.Bool("save", false, "save the session, implied by -out")
+	if code, ok := parse(fs, args); !ok {
+		return code
+	}
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return ExitUsage
+	}
+
+	input := fs.Arg(0)
+	if _, err := os.Stat(input); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return ExitNotFound
+	}
+
+	cfg, code := loadConfig(flags)
+	if cfg == nil {
+		return code
+	}
+
+	ctx := context.Background()
+	app, done, code := newApplication(ctx, cfg)
+	if app == nil {
+		return code
+	}
+	defer done()
+
+	sessionOpts := opts.sessionOptions(cfg)
+	s, err := app.TranscribeFile(ctx, input, sessionOpts, func(done, total int) {
+		fmt.Fprintf(os.Stderr, "\rTranscribed %d/%d chunks", done, total)
+	})
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to transcribe %s: %v\n", input, err)
+		return ExitFailure
+	}
+
+	for _, c := range s.Chunks {
+		if c.Error != "" {
+			fmt.Fprintf(os.Stderr, "Chunk %d: %s\n", c.Seq, c.Error)
+			continue
+		}
+		if text := strings.TrimSpace(c.Text); text != "" {
+			fmt.Println(text)
+		}
+	}
+
+	if *save || sessionOpts.OutputPath != "" {
+		id, err := app.SaveSession(s, sessionOpts.OutputPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to save session: %v\n", err)
+			return ExitFailure
+		}
+		fmt.Fprintf(os.Stderr, "Saved session %s\n", id)
+		summarize(app, id, os.Stderr, nil)
+	}
+
+	return ExitOK
+}
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/tuanta7/ekko/internal/config"
+	"github.com/tuanta7/ekko/internal/server"
+	"github.com/tuanta7/ekko/internal/ui"
+)
+
+func tui(args []string) int {
+	fs := newFlagSet("ekko", "[flags]")
+	flags := config.RegisterFlags(fs)
+	if code, ok := parse(fs, args); !ok {
+		return code
+	}
+
+	cfg, code := loadConfig(flags)
+	if cfg == nil {
+		return code
+	}
+
+	app, done, code := newApplication(context.Background(), cfg)
+	if app == nil {
+		return code
+	}
+	defer done()
+
+	if cfg.Server.Listen != "" {
+		ln, err := server.Listen(cfg.Server.Listen)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to listen on %s: %v\n", cfg.Server.Listen, err)
+			return ExitFailure
+		}
+
+		// the sessions are driven by the TUI, the server shows them, e.g. in the caption viewer
+		srv := server.New(app, server.Config{Listen: cfg.Server.Listen, ReadOnly: true})
+		ctx, stopServer := context.WithCancel(context.Background())
+		defer stopServer()
+		go func() { _ = srv.Serve(ctx, ln) }()
+	}
+
+	// Ctrl+C is a key press in the TUI, signals come from outside, e.g. kill or systemd
+	model := ui.NewModel(app, cfg)
+	program := tea.NewProgram(model, tea.WithoutSignalHandler())
+	go handleSignals(program)
+
+	if _, err := program.Run(); err != nil {
+		if errors.Is(err, tea.ErrProgramKilled) {
+			fmt.Fprintln(os.Stderr, "Quit without saving, the session will be offered for recovery on the next start")
+			return ExitInterrupted
+		}
+		fmt.Fprintf(os.Stderr, "Alas, there's been an error: %v\n", err)
+		return ExitFailure
+	}
+
+	// reported once the terminal is restored, so it stays visible
+	id, err := model.Saved()
+	if id == "" && err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to save session: %v\n", err)
+		return ExitFailure
+	}
+	if id != "" {
+		fmt.Printf("Saved session %s\n", id)
+	}
+	if id != "" && err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	if id = model.PendingSummary(); id != "" {
+		summarize(app, id, os.Stdout, nil)
+	}
+
+	return ExitOK
+}
+
+// handleSignals saves the session and quits on the first SIGINT or SIGTERM, and quits
+// at once on the second one.
+func handleSignals(program *tea.Program) {
+	sig := make(chan os.Signal, 2)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+
+	<-sig
+	program.Send(ui.ShutdownMsg{})
+	<-sig
+	program.Kill()
+}
@@ -0,0 +1,415 @@
+// Package config loads the ekko configuration from, in increasing order of precedence,
+// defaults, the user config file, a project-local override, environment variables and
+// command-line flags.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tuanta7/ekko/internal/audio"
+	"github.com/tuanta7/ekko/internal/core"
+	"github.com/tuanta7/ekko/internal/export"
+	"github.com/tuanta7/ekko/internal/format"
+	"github.com/tuanta7/ekko/internal/redact"
+	"github.com/tuanta7/ekko/internal/store"
+	"github.com/tuanta7/ekko/internal/summary"
+	"github.com/tuanta7/ekko/internal/transcriber"
+	"github.com/tuanta7/ekko/pkg/xdg"
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectFile is the project-local override, looked up in the working directory.
+const ProjectFile = ".ekko.yaml"
+
+type Config struct {
+	Transcriber Transcriber `yaml:"transcriber"`
+	Recording   Recording   `yaml:"recording"`
+	Output      Output      `yaml:"output"`
+	Storage     Storage     `yaml:"storage"`
+	Summary     Summary     `yaml:"summary"`
+	Formatting  Formatting  `yaml:"formatting"`
+	Redaction   Redaction   `yaml:"redaction"`
+	UI          UI          `yaml:"ui"`
+	Server      Server      `yaml:"server"`
+
+	// files lists the config files that were loaded, lowest precedence first.
+	files []string
+}
+
+type Transcriber struct {
+	Mode         transcriber.Mode `yaml:"mode"`
+	Model        string           `yaml:"model,omitempty"`
+	Language     string           `yaml:"language,omitempty"`
+	GeminiAPIKey string           `yaml:"gemini_api_key,omitempty"`
+}
+
+type Recording struct {
+	// Source is a PulseAudio source, empty uses the monitor of the first sink.
+	Source        string        `yaml:"source,omitempty"`
+	ChunkDuration time.Duration `yaml:"chunk_duration"`
+	// StopTimeout bounds how long stopping waits for the chunks still being transcribed.
+	StopTimeout time.Duration `yaml:"stop_timeout"`
+}
+
+type Output struct {
+	Dir              string `yaml:"dir,omitempty"`
+	FilenameTemplate string `yaml:"filename_template,omitempty"`
+	// ExportFormats are exported each time a session is saved, e.g. markdown.
+	ExportFormats []string `yaml:"export_formats,omitempty"`
+	// TextTimestamps is the timestamp granularity of the text format, see export.ParseTimestamps.
+	TextTimestamps string        `yaml:"text_timestamps,omitempty"`
+	KeepAudio      bool          `yaml:"keep_audio"`
+	AudioFormat    audio.Format  `yaml:"audio_format,omitempty"`
+	AudioRetention time.Duration `yaml:"audio_retention,omitempty"`
+}
+
+type Storage struct {
+	// Database is the SQLite database of the saved sessions, defaults to store.DefaultPath.
+	Database   string `yaml:"database,omitempty"`
+	WorkDir    string `yaml:"work_dir,omitempty"`
+	JournalDir string `yaml:"journal_dir,omitempty"`
+	// Encrypt encrypts the saved sessions, the retained audio, the journals and the
+	// search index with a key derived from the passphrase. Once encrypted, the sessions
+	// stay encrypted whatever its value.
+	Encrypt bool `yaml:"encrypt,omitempty"`
+	// Passphrase is asked on the terminal when empty.
+	Passphrase string `yaml:"passphrase,omitempty"`
+}
+
+// Summary configures the language model summarizing the sessions once they are saved.
+type Summary struct {
+	// Provider is gemini or openai, empty disables the summaries.
+	Provider summary.Provider `yaml:"provider,omitempty"`
+	Model    string           `yaml:"model,omitempty"`
+	// BaseURL replaces the provider endpoint, e.g. of an OpenAI-compatible local server.
+	BaseURL string `yaml:"base_url,omitempty"`
+	// APIKey defaults to the Gemini API key of the transcriber for the gemini provider.
+	APIKey string `yaml:"api_key,omitempty"`
+}
+
+// Formatting configures the punctuation and capitalization of the transcribed chunks.
+type Formatting struct {
+	// Mode is rules or llm, which uses the summary provider; empty keeps the raw text.
+	Mode format.Mode `yaml:"mode,omitempty"`
+	// ParagraphGap is the gap between two chunks that starts a paragraph.
+	ParagraphGap time.Duration `yaml:"paragraph_gap,omitempty"`
+}
+
+// Redaction configures the removal of the personal data from the transcripts.
+type Redaction struct {
+	// Mode is mask, hash or drop, empty disables the redaction.
+	Mode redact.Mode `yaml:"mode,omitempty"`
+	// Detectors are the built-in detectors, see redact.Builtins.
+	Detectors    []string              `yaml:"detectors"`
+	Rules        []RedactionRule       `yaml:"rules,omitempty"`
+	Dictionaries []RedactionDictionary `yaml:"dictionaries,omitempty"`
+	// HashKey keys the hashes of the hash mode, so they cannot be reversed by hashing
+	// every phone number.
+	HashKey string `yaml:"hash_key,omitempty"`
+}
+
+// RedactionRule detects the matches of a regular expression, named Name in the masks.
+type RedactionRule struct {
+	Name    string `yaml:"name"`
+	Pattern string `yaml:"pattern"`
+}
+
+// RedactionDictionary detects the terms listed in a file, one per line.
+type RedactionDictionary struct {
+	Name string `yaml:"name"`
+	Path string `yaml:"path"`
+}
+
+type Server struct {
+	// Listen is a TCP address or unix:/path/to/socket, see server.Listen.
+	Listen string `yaml:"listen,omitempty"`
+}
+
+type UI struct {
+	TranscriptWidth  int `yaml:"transcript_width"`
+	TranscriptHeight int `yaml:"transcript_height"`
+}
+
+func Default() *Config {
+	return &Config{
+		Transcriber: Transcriber{
+			Mode: transcriber.WhisperMode,
+		},
+		Recording: Recording{
+			ChunkDuration: 10 * time.Second,
+			StopTimeout:   core.DefaultStopTimeout,
+		},
+		Output: Output{
+			FilenameTemplate: core.DefaultFilenameTemplate,
+			AudioFormat:      audio.FormatOpus,
+		},
+		Redaction: Redaction{
+			Detectors: redact.Builtins(),
+		},
+		UI: UI{
+			TranscriptWidth:  100,
+			TranscriptHeight: 10,
+		},
+	}
+}
+
+// UserFile returns the path of the user config file, $XDG_CONFIG_HOME/ekko/config.yaml.
+func UserFile() string {
+	return filepath.Join(xdg.ConfigHome(), "ekko", "config.yaml")
+}
+
+// Load builds the configuration from defaults, config files and the environment. When
+// path is set it replaces the user and project files. Use Flags.Load to apply
+// command-line flags as well.
+func Load(path string) (*Config, error) {
+	c, err := load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return c, c.Validate()
+}
+
+func load(path string) (*Config, error) {
+	c := Default()
+
+	files := []string{UserFile(), ProjectFile}
+	if path != "" {
+		files = []string{path}
+	}
+
+	for _, file := range files {
+		loaded, err := c.mergeFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if loaded {
+			c.files = append(c.files, file)
+		} else if path != "" {
+			return nil, fmt.Errorf("config file %s not found", path)
+		}
+	}
+
+	if err := c.applyEnv(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (c *Config) mergeFile(path string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	// decoding into the populated struct only overrides the keys present in the file
+	if err = yaml.Unmarshal(data, c); err != nil {
+		return false, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return true, nil
+}
+
+func (c *Config) applyEnv() error {
+	for _, s := range settings {
+		if s.env == "" {
+			continue
+		}
+
+		v, ok := os.LookupEnv(s.env)
+		if !ok || v == "" {
+			continue
+		}
+
+		if err := s.set(c, v); err != nil {
+			return fmt.Errorf("%s: %w", s.env, err)
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) Validate() error {
+	switch c.Transcriber.Mode {
+	case transcriber.WhisperMode, transcriber.GeminiMode:
+	default:
+		return fmt.Errorf("invalid transcriber mode %q, must be one of: whisper, gemini", c.Transcriber.Mode)
+	}
+
+	if c.Recording.ChunkDuration < time.Second {
+		return fmt.Errorf("chunk duration must be at least 1s, got %s", c.Recording.ChunkDuration)
+	}
+
+	if c.Recording.StopTimeout < 0 {
+		return fmt.Errorf("stop timeout must not be negative, got %s", c.Recording.StopTimeout)
+	}
+
+	if _, err := audio.ParseFormat(string(c.Output.AudioFormat)); err != nil {
+		return err
+	}
+
+	for _, format := range c.Output.ExportFormats {
+		if _, err := export.Get(format, export.Options{}); err != nil {
+			return err
+		}
+	}
+
+	if _, err := export.ParseTimestamps(c.Output.TextTimestamps); err != nil {
+		return err
+	}
+
+	if c.Storage.Encrypt && len(c.Output.ExportFormats) > 0 {
+		return errors.New("the automatic exports are written in plaintext, unset output.export_formats to encrypt the sessions")
+	}
+
+	switch c.Summary.Provider {
+	case "", summary.GeminiProvider, summary.OpenAIProvider:
+	default:
+		return fmt.Errorf("invalid summary provider %q, must be one of: gemini, openai", c.Summary.Provider)
+	}
+
+	switch c.Formatting.Mode {
+	case "", format.RulesMode:
+	case format.LLMMode:
+		if c.Summary.Provider == "" {
+			return errors.New("the llm formatting mode uses the summary provider, set summary.provider")
+		}
+	default:
+		return fmt.Errorf("invalid formatting mode %q, must be one of: rules, llm", c.Formatting.Mode)
+	}
+
+	if c.Formatting.ParagraphGap < 0 {
+		return fmt.Errorf("paragraph gap must not be negative, got %s", c.Formatting.ParagraphGap)
+	}
+
+	if c.Redaction.Mode != "" {
+		if _, err := c.redactor(nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Files returns the config files that were loaded, lowest precedence first.
+func (c *Config) Files() []string {
+	return c.files
+}
+
+func (c *Config) Core() core.Config {
+	return core.Config{
+		OutputDir:        expandHome(c.Output.Dir),
+		FilenameTemplate: c.Output.FilenameTemplate,
+		ExportFormats:    c.Output.ExportFormats,
+		Export:           c.ExportOptions(),
+		WorkDir:          expandHome(c.Storage.WorkDir),
+		JournalDir:       expandHome(c.Storage.JournalDir),
+		KeepAudio:        c.Output.KeepAudio,
+		AudioFormat:      c.Output.AudioFormat,
+		AudioRetention:   c.Output.AudioRetention,
+		StopTimeout:      c.Recording.StopTimeout,
+		ParagraphGap:     c.Formatting.ParagraphGap,
+	}.WithDefaults()
+}
+
+// ExportOptions returns the options of the export formats, validated by Validate.
+func (c *Config) ExportOptions() export.Options {
+	every, _ := export.ParseTimestamps(c.Output.TextTimestamps)
+	return export.Options{TimestampEvery: every}
+}
+
+// Database returns the path of the session database.
+func (c *Config) Database() string {
+	if c.Storage.Database == "" {
+		return store.DefaultPath()
+	}
+	return expandHome(c.Storage.Database)
+}
+
+func (c *Config) TranscriberOptions() transcriber.Options {
+	return transcriber.Options{
+		APIKey:   c.Transcriber.GeminiAPIKey,
+		Model:    expandHome(c.Transcriber.Model),
+		Language: c.Transcriber.Language,
+	}
+}
+
+// SummaryOptions returns the options of the summary provider, the provider is empty
+// when the summaries are disabled.
+func (c *Config) SummaryOptions() (summary.Provider, summary.Options) {
+	opts := summary.Options{
+		APIKey:  c.Summary.APIKey,
+		Model:   c.Summary.Model,
+		BaseURL: c.Summary.BaseURL,
+	}
+	if opts.APIKey == "" && c.Summary.Provider == summary.GeminiProvider {
+		opts.APIKey = c.Transcriber.GeminiAPIKey
+	}
+	return c.Summary.Provider, opts
+}
+
+// Redactor returns the redactor of the transcripts, nil when the redaction is disabled.
+// It reads the dictionaries.
+func (c *Config) Redactor() (*redact.Redactor, error) {
+	if c.Redaction.Mode == "" {
+		return nil, nil
+	}
+
+	var detectors []redact.Detector
+	for _, d := range c.Redaction.Dictionaries {
+		dict, err := redact.LoadDictionary(d.Name, expandHome(d.Path))
+		if err != nil {
+			return nil, err
+		}
+		detectors = append(detectors, dict)
+	}
+	return c.redactor(detectors)
+}
+
+// redactor creates the redactor with the rules and the built-in detectors after the
+// given ones.
+func (c *Config) redactor(detectors []redact.Detector) (*redact.Redactor, error) {
+	for _, rule := range c.Redaction.Rules {
+		if rule.Name == "" {
+			return nil, fmt.Errorf("redaction rule %q has no name", rule.Pattern)
+		}
+		d, err := redact.NewRegex(rule.Name, rule.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		detectors = append(detectors, d)
+	}
+
+	for _, name := range c.Redaction.Detectors {
+		d, err := redact.Builtin(name)
+		if err != nil {
+			return nil, err
+		}
+		detectors = append(detectors, d)
+	}
+
+	return redact.New(c.Redaction.Mode, []byte(c.Redaction.HashKey), detectors...)
+}
+
+// expandHome replaces a leading ~ with the home directory, as a shell would.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+
+	return filepath.Join(home, path[1:])
+}
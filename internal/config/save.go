@@ -0,0 +1,140 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Save writes a setting back to the config file that defines it, or to the user config
+// file when none does. Only that key is changed, the rest of the file, including its
+// comments, is preserved. Values coming from the environment or flags are never written.
+func (c *Config) Save(key string, value any) error {
+	target := UserFile()
+	// the last loaded file has the highest precedence
+	for i := len(c.files) - 1; i >= 0; i-- {
+		if defines, err := fileDefines(c.files[i], key); err == nil && defines {
+			target = c.files[i]
+			break
+		}
+	}
+
+	return setInFile(target, key, value)
+}
+
+func fileDefines(path, key string) (bool, error) {
+	doc, err := readDocument(path)
+	if err != nil {
+		return false, err
+	}
+
+	node := doc.Content[0]
+	for _, part := range strings.Split(key, ".") {
+		node = lookupKey(node, part)
+		if node == nil {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func setInFile(path, key string, value any) error {
+	doc, err := readDocument(path)
+	if err != nil {
+		return err
+	}
+
+	var encoded yaml.Node
+	if err = encoded.Encode(value); err != nil {
+		return err
+	}
+
+	node := doc.Content[0]
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		child := lookupKey(node, part)
+		if child == nil {
+			child = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: part}, child)
+		}
+
+		if i < len(parts)-1 && child.Kind != yaml.MappingNode {
+			*child = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		}
+
+		if i == len(parts)-1 {
+			// keep the comments attached to the previous value
+			encoded.HeadComment, encoded.LineComment, encoded.FootComment = child.HeadComment, child.LineComment, child.FootComment
+			*child = encoded
+		}
+		node = child
+	}
+
+	data, err := yaml.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	// replace atomically so a crash never leaves a truncated config file
+	tmp := path + ".tmp"
+	if err = os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+// readDocument parses a YAML file into a document whose root is a mapping. A missing
+// or empty file yields an empty mapping.
+func readDocument(path string) (*yaml.Node, error) {
+	doc := &yaml.Node{
+		Kind:    yaml.DocumentNode,
+		Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}},
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return doc, nil
+		}
+		return nil, err
+	}
+
+	var parsed yaml.Node
+	if err = yaml.Unmarshal(data, &parsed); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if parsed.Kind == 0 || len(parsed.Content) == 0 {
+		return doc, nil
+	}
+
+	if parsed.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s: top level must be a mapping", path)
+	}
+
+	return &parsed, nil
+}
+
+func lookupKey(mapping *yaml.Node, key string) *yaml.Node {
+	if mapping.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,311 @@
+package config
+
+import (
+	"flag"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tuanta7/ekko/internal/audio"
+	"github.com/tuanta7/ekko/internal/format"
+	"github.com/tuanta7/ekko/internal/redact"
+	"github.com/tuanta7/ekko/internal/summary"
+	"github.com/tuanta7/ekko/internal/transcriber"
+)
+
+// setting describes a config key and how it is set from the environment and flags.
+type setting struct {
+	key   string // dotted path in the config file
+	env   string
+	flag  string
+	usage string
+	set   func(c *Config, v string) error
+}
+
+var settings = []setting{
+	{
+		key: "transcriber.mode", env: "TRANSCRIBER_MODE", flag: "mode",
+		usage: "transcription backend: whisper, gemini",
+		set: func(c *Config, v string) error {
+			c.Transcriber.Mode = transcriber.Mode(v)
+			return nil
+		},
+	},
+	{
+		key: "transcriber.model", env: "TRANSCRIBER_MODEL", flag: "model",
+		usage: "whisper model path or gemini model name",
+		set: func(c *Config, v string) error {
+			c.Transcriber.Model = v
+			return nil
+		},
+	},
+	{
+		key: "transcriber.language", env: "EKKO_LANGUAGE", flag: "language",
+		usage: "spoken language, e.g. en, or auto to detect it (whisper only)",
+		set: func(c *Config, v string) error {
+			c.Transcriber.Language = v
+			return nil
+		},
+	},
+	{
+		key: "transcriber.gemini_api_key", env: "GEMINI_API_KEY",
+		set: func(c *Config, v string) error {
+			c.Transcriber.GeminiAPIKey = v
+			return nil
+		},
+	},
+	{
+		key: "recording.source", env: "EKKO_SOURCE", flag: "source",
+		usage: "PulseAudio source to record, defaults to the monitor of the first sink",
+		set: func(c *Config, v string) error {
+			c.Recording.Source = v
+			return nil
+		},
+	},
+	{
+		key: "recording.chunk_duration", env: "EKKO_CHUNK_DURATION", flag: "chunk-duration",
+		usage: "length of the audio chunks sent to the transcriber, e.g. 10s",
+		set: func(c *Config, v string) (err error) {
+			c.Recording.ChunkDuration, err = time.ParseDuration(v)
+			return err
+		},
+	},
+	{
+		key: "recording.stop_timeout", env: "EKKO_STOP_TIMEOUT", flag: "stop-timeout",
+		usage: "how long stopping waits for the chunks still being transcribed, e.g. 30s",
+		set: func(c *Config, v string) (err error) {
+			c.Recording.StopTimeout, err = time.ParseDuration(v)
+			return err
+		},
+	},
+	{
+		key: "output.dir", env: "EKKO_OUTPUT_DIR", flag: "output-dir",
+		usage: "directory where the kept audio and the exports are saved",
+		set: func(c *Config, v string) error {
+			c.Output.Dir = v
+			return nil
+		},
+	},
+	{
+		key: "output.filename_template", env: "EKKO_FILENAME_TEMPLATE", flag: "filename-template",
+		usage: "export filename template, e.g. {date}-{title}",
+		set: func(c *Config, v string) error {
+			c.Output.FilenameTemplate = v
+			return nil
+		},
+	},
+	{
+		key: "output.export_formats", env: "EKKO_EXPORT_FORMATS", flag: "export",
+		usage: "formats exported each time a session is saved, comma separated, e.g. markdown,text",
+		set: func(c *Config, v string) error {
+			c.Output.ExportFormats = splitList(v)
+			return nil
+		},
+	},
+	{
+		key: "output.text_timestamps", env: "EKKO_TEXT_TIMESTAMPS", flag: "text-timestamps",
+		usage: "timestamps of the text export: chunk, none, or at most one per duration, e.g. 1m",
+		set: func(c *Config, v string) error {
+			c.Output.TextTimestamps = v
+			return nil
+		},
+	},
+	{
+		key: "output.keep_audio", env: "EKKO_KEEP_AUDIO", flag: "keep-audio",
+		usage: "keep the session recording: true, false",
+		set: func(c *Config, v string) (err error) {
+			c.Output.KeepAudio, err = strconv.ParseBool(v)
+			return err
+		},
+	},
+	{
+		key: "output.audio_format", env: "EKKO_AUDIO_FORMAT", flag: "audio-format",
+		usage: "format of kept recordings: opus, flac",
+		set: func(c *Config, v string) (err error) {
+			c.Output.AudioFormat, err = audio.ParseFormat(v)
+			return err
+		},
+	},
+	{
+		key: "output.audio_retention", env: "EKKO_AUDIO_RETENTION", flag: "audio-retention",
+		usage: "how long kept recordings are stored, e.g. 720h, 0 keeps them forever",
+		set: func(c *Config, v string) (err error) {
+			c.Output.AudioRetention, err = time.ParseDuration(v)
+			return err
+		},
+	},
+	{
+		key: "server.listen", env: "EKKO_LISTEN", flag: "listen",
+		usage: "address of the local API, e.g. 127.0.0.1:7777 or unix:/run/user/1000/ekko.sock",
+		set: func(c *Config, v string) error {
+			c.Server.Listen = v
+			return nil
+		},
+	},
+	{
+		key: "summary.provider", env: "EKKO_SUMMARY_PROVIDER", flag: "summary",
+		usage: "summarize the sessions once saved with: gemini, openai, empty disables it",
+		set: func(c *Config, v string) error {
+			c.Summary.Provider = summary.Provider(v)
+			return nil
+		},
+	},
+	{
+		key: "summary.model", env: "EKKO_SUMMARY_MODEL", flag: "summary-model",
+		usage: "model writing the summaries, e.g. gpt-4o-mini",
+		set: func(c *Config, v string) error {
+			c.Summary.Model = v
+			return nil
+		},
+	},
+	{
+		key: "summary.base_url", env: "EKKO_SUMMARY_BASE_URL", flag: "summary-base-url",
+		usage: "endpoint of the summary provider, e.g. http://localhost:11434/v1 for Ollama",
+		set: func(c *Config, v string) error {
+			c.Summary.BaseURL = v
+			return nil
+		},
+	},
+	{
+		key: "summary.api_key", env: "EKKO_SUMMARY_API_KEY",
+		set: func(c *Config, v string) error {
+			c.Summary.APIKey = v
+			return nil
+		},
+	},
+	{
+		key: "formatting.mode", env: "EKKO_FORMATTING", flag: "formatting",
+		usage: "restore the punctuation of the transcript with: rules, llm, empty keeps it raw",
+		set: func(c *Config, v string) error {
+			c.Formatting.Mode = format.Mode(v)
+			return nil
+		},
+	},
+	{
+		key: "formatting.paragraph_gap", env: "EKKO_PARAGRAPH_GAP", flag: "paragraph-gap",
+		usage: "gap between two chunks that starts a paragraph, e.g. 2s",
+		set: func(c *Config, v string) (err error) {
+			c.Formatting.ParagraphGap, err = time.ParseDuration(v)
+			return err
+		},
+	},
+	{
+		key: "redaction.mode", env: "EKKO_REDACTION", flag: "redact",
+		usage: "remove the personal data from the transcripts: mask, hash, drop, empty disables it",
+		set: func(c *Config, v string) error {
+			c.Redaction.Mode = redact.Mode(v)
+			return nil
+		},
+	},
+	{
+		key: "redaction.detectors", env: "EKKO_REDACTION_DETECTORS", flag: "redact-detectors",
+		usage: "built-in detectors of the redaction, comma separated: card, email, phone",
+		set: func(c *Config, v string) error {
+			c.Redaction.Detectors = splitList(v)
+			return nil
+		},
+	},
+	{
+		key: "redaction.hash_key", env: "EKKO_REDACTION_HASH_KEY",
+		set: func(c *Config, v string) error {
+			c.Redaction.HashKey = v
+			return nil
+		},
+	},
+	{
+		key: "storage.database", env: "EKKO_DATABASE", flag: "database",
+		usage: "SQLite database of the saved sessions",
+		set: func(c *Config, v string) error {
+			c.Storage.Database = v
+			return nil
+		},
+	},
+	{
+		key: "storage.work_dir", env: "EKKO_WORK_DIR",
+		set: func(c *Config, v string) error {
+			c.Storage.WorkDir = v
+			return nil
+		},
+	},
+	{
+		key: "storage.journal_dir", env: "EKKO_JOURNAL_DIR",
+		set: func(c *Config, v string) error {
+			c.Storage.JournalDir = v
+			return nil
+		},
+	},
+	{
+		key: "storage.encrypt", env: "EKKO_ENCRYPT", flag: "encrypt",
+		usage: "encrypt the saved sessions and the retained audio with a passphrase: true, false",
+		set: func(c *Config, v string) (err error) {
+			c.Storage.Encrypt, err = strconv.ParseBool(v)
+			return err
+		},
+	},
+	{
+		key: "storage.passphrase", env: "EKKO_PASSPHRASE",
+		set: func(c *Config, v string) error {
+			c.Storage.Passphrase = v
+			return nil
+		},
+	},
+}
+
+// Flags collects command-line overrides. They are registered before the config is
+// loaded and applied on top of it, so they take precedence over every other layer.
+type Flags struct {
+	path      string
+	overrides []func(c *Config) error
+}
+
+// RegisterFlags adds -config and a flag for every setting that has one.
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	f := &Flags{}
+	fs.StringVar(&f.path, "config", "", "config file, replaces the user and project config files")
+
+	for _, s := range settings {
+		if s.flag == "" {
+			continue
+		}
+
+		fs.Func(s.flag, s.usage, func(v string) error {
+			// validate early so the flag package reports the error with the usage
+			if err := s.set(Default(), v); err != nil {
+				return err
+			}
+			f.overrides = append(f.overrides, func(c *Config) error {
+				return s.set(c, v)
+			})
+			return nil
+		})
+	}
+
+	return f
+}
+
+// Load loads the configuration and applies the flags parsed so far.
+func (f *Flags) Load() (*Config, error) {
+	c, err := load(f.path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, override := range f.overrides {
+		if err = override(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, c.Validate()
+}
+
+// splitList splits a comma separated value, e.g. the export formats.
+func splitList(v string) []string {
+	var items []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -5,77 +5,231 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/tuanta7/ekko/internal/audio"
+	"github.com/tuanta7/ekko/internal/encrypt"
+	"github.com/tuanta7/ekko/internal/export"
+	"github.com/tuanta7/ekko/internal/session"
 	"github.com/tuanta7/ekko/internal/transcriber"
 	"github.com/tuanta7/ekko/pkg/queue"
-	"github.com/tuanta7/ekko/pkg/x"
+	"github.com/tuanta7/ekko/pkg/xdg"
 )
 
-type TranscriptionChunk struct {
-	Timestamp int64  `json:"timestamp"`
-	Text      string `json:"text"`
-	Error     error  `json:"error,omitempty"`
+// Version is the application version recorded in session manifests, it is set at build time.
+var Version = "dev"
+
+// DefaultStopTimeout is how long Stop waits for the chunks still being transcribed.
+const DefaultStopTimeout = 15 * time.Second
+
+// cancelTimeout is how long Stop waits for the workers once the stop timeout expired
+// and the chunks still being transcribed are abandoned.
+const cancelTimeout = 2 * time.Second
+
+// ErrNoSession is returned by Stop when there is no session to stop.
+var ErrNoSession = errors.New("no active session")
+
+// ErrTranscribing is returned when the transcriber is busy with a file or a saved session.
+var ErrTranscribing = errors.New("a transcription is already running")
+
+type Config struct {
+	// OutputDir holds the retained audio and the exports, defaults to $XDG_DATA_HOME/ekko/transcripts.
+	OutputDir string
+	// FilenameTemplate names the exports, see RenderFilename.
+	FilenameTemplate string
+	// ExportFormats are written to the exports directory each time a session is saved,
+	// unless the sessions are encrypted.
+	ExportFormats []string
+	Export        export.Options
+	// WorkDir holds the per-session temporary directories, defaults to $XDG_CACHE_HOME/ekko.
+	WorkDir string
+	// JournalDir holds the journals of sessions in progress, defaults to $XDG_STATE_HOME/ekko/journal.
+	JournalDir string
+	// KeepAudio archives the session recording in OutputDir/audio.
+	KeepAudio   bool
+	AudioFormat audio.Format
+	// AudioRetention is how long archived recordings are kept, zero keeps them forever.
+	AudioRetention time.Duration
+	// StopTimeout bounds how long Stop waits for the chunks still being transcribed.
+	StopTimeout time.Duration
+	// Summarizer writes the session summaries, nil disables them.
+	Summarizer Summarizer
+	// Formatter formats each chunk once transcribed, nil keeps the raw text.
+	Formatter Formatter
+	// ParagraphGap is the gap between two chunks that starts a paragraph, see
+	// session.StartsParagraph.
+	ParagraphGap time.Duration
+	// Redactor removes the personal data from the transcripts, nil keeps them as
+	// transcribed.
+	Redactor Redactor
+	// Key seals the retained audio, the journals and the search index, as the store
+	// seals the sessions. Nil writes them in plaintext.
+	Key *encrypt.Key
+}
+
+func DefaultOutputDir() string {
+	return filepath.Join(xdg.DataHome(), "ekko", "transcripts")
+}
+
+func DefaultWorkDir() string {
+	return filepath.Join(xdg.CacheHome(), "ekko")
+}
+
+func DefaultJournalDir() string {
+	return filepath.Join(xdg.StateHome(), "ekko", "journal")
+}
+
+type SessionOptions struct {
+	ChunkDuration time.Duration
+	Title         string
+	Tags          []string
+	// OutputPath also exports the transcript to this file, in the format of its
+	// extension, JSON by default.
+	OutputPath string
 }
 
 type Application struct {
-	wg            sync.WaitGroup
-	mu            sync.Mutex
-	isRunning     bool
-	transcription *sync.Map
+	wg        sync.WaitGroup
+	mu        sync.Mutex
+	isRunning bool
+	unsaved   bool // started and not stopped yet, the workers may have finished on their own
+	// transcribing is set while the transcriber is reserved by TranscribeFile or Retranscribe
+	transcribing bool
+	startedAt    time.Time
+	workDir      string
+	output       string
+	drained      chan struct{}
+	done         chan struct{}
+	cfg          Config
+
+	sessionMu sync.Mutex
+	session   *session.Session
+	journal   *session.Journal
+	retained  []retainedChunk
+	// saved is set once Stop saved the session and removed the journal, what the
+	// workers add after is saved again when they finish
+	saved  bool
+	saveMu sync.Mutex // orders the saves of the session
+
+	pauseMu     sync.Mutex
+	paused      bool
+	pause       session.Pause
+	resumed     chan struct{} // closed when the pause ends
+	cancelChunk context.CancelFunc
 
 	ctx    context.Context
 	cancel context.CancelFunc
+	// recordCtx is canceled first when stopping, so recording ends while the
+	// chunks already recorded are still transcribed.
+	recordCtx     context.Context
+	stopRecording context.CancelFunc
+
+	broadcaster broadcaster
+	indexMu     sync.Mutex
 
 	queue    *queue.RecordQueue
 	counter  atomic.Uint32
 	recorder *audio.Recorder
 	trClient transcriber.Client
+	store    Store
 }
 
-func NewApplication(recorder *audio.Recorder, client transcriber.Client) *Application {
+func NewApplication(recorder *audio.Recorder, client transcriber.Client, store Store, cfg Config) *Application {
 	return &Application{
 		recorder: recorder,
 		trClient: client,
+		store:    store,
+		cfg:      cfg.WithDefaults(),
 	}
 }
 
-func (a *Application) Start(chunkDuration time.Duration) (<-chan string, error) {
+// WithDefaults returns the config with the default value of every unset field.
+func (cfg Config) WithDefaults() Config {
+	if cfg.OutputDir == "" {
+		cfg.OutputDir = DefaultOutputDir()
+	}
+	if cfg.FilenameTemplate == "" {
+		cfg.FilenameTemplate = DefaultFilenameTemplate
+	}
+	if cfg.WorkDir == "" {
+		cfg.WorkDir = DefaultWorkDir()
+	}
+	if cfg.JournalDir == "" {
+		cfg.JournalDir = DefaultJournalDir()
+	}
+	if cfg.AudioFormat == "" {
+		cfg.AudioFormat = audio.FormatOpus
+	}
+	if cfg.StopTimeout == 0 {
+		cfg.StopTimeout = DefaultStopTimeout
+	}
+	if cfg.ParagraphGap == 0 {
+		cfg.ParagraphGap = DefaultParagraphGap
+	}
+
+	return cfg
+}
+
+func (a *Application) Start(opts SessionOptions) (<-chan Event, error) {
 	a.mu.Lock()
-	if a.isRunning {
+	defer a.mu.Unlock()
+
+	if a.isRunning || a.unsaved {
 		return nil, errors.New("session already running")
 	}
+	if a.transcribing {
+		return nil, ErrTranscribing
+	}
 
-	if err := a.initSession(); err != nil {
+	if err := a.initSession(opts); err != nil {
 		return nil, err
 	}
 
-	if err := os.Mkdir(".tmp", 0755); err != nil {
+	journal, err := session.CreateJournal(a.cfg.JournalDir, a.session.Manifest, a.cfg.Key)
+	if err != nil {
+		a.cancel()
 		return nil, err
 	}
+	a.journal = journal
+
+	workDir, err := createSessionDir(a.cfg.WorkDir)
+	if err != nil {
+		a.cancel()
+		_ = journal.Remove()
+		return nil, err
+	}
+	a.workDir = workDir
 
 	a.isRunning = true
-	a.mu.Unlock()
+	a.unsaved = true
+
+	// the workers emit to events, dispatch forwards them to the stream and the subscribers
+	events := make(chan Event, 10)
+	stream := make(chan Event, 10)
+	go a.dispatch(a.ctx, events, stream)
+
+	start := newEvent(EventSessionStart)
+	start.Text = a.session.Manifest.ID
+	events <- start
 
-	stream := make(chan string, 10)
 	a.wg = sync.WaitGroup{}
 	a.wg.Add(2)
 
 	go func() {
 		defer func() {
 			a.wg.Done()
-			// time.Sleep(100 * time.Second) // for debugging
-			close(stream) // Signal consumers when done
 			if r := recover(); r != nil {
 				_, _ = fmt.Fprintf(os.Stderr, "transcribe panic recovered: %v\n", r)
 			}
 		}()
 
-		if err := a.transcribe(stream); err != nil && !errors.Is(err, context.Canceled) {
-			stream <- err.Error()
+		if err := a.transcribe(events); err != nil && !errors.Is(err, context.Canceled) {
+			ev := newEvent(EventError)
+			ev.Err = err
+			tryEmit(events, ev)
 		}
 	}()
 
@@ -87,23 +241,59 @@ func (a *Application) Start(chunkDuration time.Duration) (<-chan string, error)
 			}
 		}()
 
-		if err := a.record(chunkDuration); err != nil && !errors.Is(err, context.Canceled) {
-			stream <- err.Error()
+		if err := a.record(events, opts.ChunkDuration); err != nil && !errors.Is(err, context.Canceled) {
+			ev := newEvent(EventError)
+			ev.Err = err
+			tryEmit(events, ev)
 		}
 	}()
 
+	a.drained = make(chan struct{})
+	a.done = make(chan struct{})
 	go func() {
 		// when both workers finish, mark the session as not running
 		a.wg.Wait()
+		close(a.drained)
+
+		if a.cfg.KeepAudio {
+			if err := a.archiveAudio(workDir); err != nil {
+				ev := newEvent(EventError)
+				ev.Err = err
+				tryEmit(events, ev)
+			}
+		}
+
+		if err := a.saveLate(); err != nil {
+			ev := newEvent(EventError)
+			ev.Err = err
+			tryEmit(events, ev)
+		}
+
+		// nothing reads the audio chunks anymore, it is now safe to remove them
+		if err := os.RemoveAll(workDir); err != nil {
+			ev := newEvent(EventError)
+			ev.Err = fmt.Errorf("failed to remove session directory: %w", err)
+			tryEmit(events, ev)
+		}
+
+		a.mu.Lock()
 		a.isRunning = false
+		a.mu.Unlock()
+		close(a.done)
+
+		tryEmit(events, newEvent(EventSessionEnd))
+		close(events) // dispatch closes the stream
 	}()
 
 	return stream, nil
 }
 
-func (a *Application) initSession() error {
+func (a *Application) initSession(opts SessionOptions) error {
 	a.ctx, a.cancel = context.WithCancel(context.Background())
-	a.transcription = &sync.Map{}
+	a.recordCtx, a.stopRecording = context.WithCancel(a.ctx)
+	a.paused = false
+	a.cancelChunk = nil
+	a.startedAt = time.Now()
 	a.queue = queue.NewRecordQueue()
 	a.counter.Store(0)
 
@@ -116,55 +306,216 @@ func (a *Application) initSession() error {
 		return fmt.Errorf("failed to reset transcriber context: %w", err)
 	}
 
+	info := a.trClient.Info()
+	a.session = session.New()
+	a.output = opts.OutputPath
+	a.retained = nil
+	a.saved = false
+	a.session.Manifest.Title = a.redact(opts.Title)
+	a.session.Manifest.Tags = a.redactTags(opts.Tags)
+	a.session.Manifest.StartedAt = a.startedAt
+	a.session.Manifest.Backend = string(info.Mode)
+	a.session.Manifest.Model = info.Model
+	a.session.Manifest.Language = info.Language
+	a.session.Manifest.ChunkDurationMS = opts.ChunkDuration.Milliseconds()
+	a.session.Manifest.AppVersion = Version
+	a.session.Manifest.Revision = 1
+
 	return nil
 }
 
+// reserveTranscriber claims the transcriber for a transcription outside of a live
+// session until release is called, the transcriber context cannot be shared. Start
+// fails with ErrTranscribing meanwhile.
+func (a *Application) reserveTranscriber() (release func(), err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.isRunning || a.unsaved {
+		return nil, errors.New("session already running")
+	}
+	if a.transcribing {
+		return nil, ErrTranscribing
+	}
+
+	a.transcribing = true
+	return func() {
+		a.mu.Lock()
+		a.transcribing = false
+		a.mu.Unlock()
+	}, nil
+}
+
+func (a *Application) IsRunning() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.isRunning
+}
+
+// Stop ends the session and saves it, also when the session already ended on its own,
+// and returns its ID. Recording stops at once, the chunks already recorded are
+// transcribed within the stop timeout. The event stream must be read until it is
+// closed, or the remaining chunks are only saved when the timeout expires. The ID is
+// returned with the error when the session was saved but the journal removal, an export
+// or the indexing failed.
 func (a *Application) Stop() (string, error) {
 	a.mu.Lock()
-	if !a.isRunning {
+	if !a.unsaved {
 		a.mu.Unlock()
-		return "", errors.New("no active session")
+		return "", ErrNoSession
 	}
+	a.unsaved = false
 
-	if a.cancel != nil {
-		a.cancel()
-	}
+	a.stopRecording()
+	drained, done := a.drained, a.done
+	a.mu.Unlock()
 
-	if err := os.RemoveAll(".tmp"); err != nil {
-		return "", err
+	a.pauseMu.Lock()
+	if a.paused {
+		// the pause lasts until the end of the session
+		_ = a.endPause()
 	}
+	a.pauseMu.Unlock()
 
-	a.mu.Unlock()
+	timer := time.NewTimer(a.cfg.StopTimeout)
+	defer timer.Stop()
 
-	ch := make(chan struct{})
-	go func() {
-		a.wg.Wait() // workers finished
-		close(ch)
-	}()
+	select {
+	case <-drained:
+	case <-timer.C:
+		// abandon the chunks still being transcribed, the workers end with the context
+		a.cancel()
+		timer.Reset(cancelTimeout)
+		select {
+		case <-drained:
+		case <-timer.C:
+			// save what we have, the session is saved again once the workers finish
+		}
+	}
+	a.cancel()
 
 	select {
-	case <-ch: // channel closed
-	case <-time.After(5 * time.Second):
+	case <-drained:
+		<-done // audio archived and session directory removed
+	default:
 	}
 
-	filename, err := a.save()
+	s, err := a.save()
 	if err != nil {
+		// keep the journal, the session can be recovered on the next start
+		_ = a.journal.Close()
 		return "", err
 	}
 
-	return filename, nil
+	var errs []error
+	if err = a.journal.Remove(); err != nil {
+		errs = append(errs, fmt.Errorf("session saved but failed to remove journal: %w", err))
+	}
+	if err = a.finishSave(s, a.output); err != nil {
+		errs = append(errs, err)
+	}
+
+	return s.Manifest.ID, errors.Join(errs...)
 }
 
-func (a *Application) save() (string, error) {
-	data, err := x.MarshalIndentSyncMap(a.transcription)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal session: %w", err)
+func (a *Application) setSource(source string) error {
+	a.sessionMu.Lock()
+	defer a.sessionMu.Unlock()
+	a.session.Manifest.Source = source
+	return a.journal.AppendManifest(a.session.Manifest)
+}
+
+// appendChunk adds the chunk to the transcript and persists it in the journal.
+func (a *Application) appendChunk(c session.Chunk) error {
+	a.sessionMu.Lock()
+	defer a.sessionMu.Unlock()
+	a.session.Append(c)
+	if a.saved {
+		return nil // the journal is gone, saveLate saves the chunk
 	}
+	return a.journal.AppendChunk(c)
+}
+
+func (a *Application) save() (*session.Session, error) {
+	a.saveMu.Lock()
+	defer a.saveMu.Unlock()
+
+	a.sessionMu.Lock()
+	a.session.Manifest.EndedAt = time.Now()
+	s := a.session.Clone() // the workers may still append chunks
+	a.sessionMu.Unlock()
 
-	filename := fmt.Sprintf("transcript-%s.json", time.Now().Format("20060102-150405"))
-	if err = os.WriteFile(filename, data, 0644); err != nil {
-		return "", fmt.Errorf("failed to write file: %w", err)
+	if err := a.store.Save(s); err != nil {
+		return nil, fmt.Errorf("failed to save session: %w", err)
 	}
 
-	return filename, nil
+	a.sessionMu.Lock()
+	a.saved = true
+	a.sessionMu.Unlock()
+	return s, nil
+}
+
+// saveLate saves the session again when Stop saved it before the workers finished, with
+// the chunks transcribed and the recording archived since. It must only be called once
+// the workers have finished.
+func (a *Application) saveLate() error {
+	a.saveMu.Lock()
+	defer a.saveMu.Unlock()
+
+	a.sessionMu.Lock()
+	saved := a.saved
+	s := a.session.Clone()
+	a.sessionMu.Unlock()
+
+	if !saved {
+		return nil
+	}
+
+	if err := a.store.Save(s); err != nil {
+		return fmt.Errorf("failed to save the end of the session: %w", err)
+	}
+	return a.indexSession(s)
+}
+
+// finishSave exports a stored session to exportPath when one is given and in the
+// configured export formats, and adds the session to the search index.
+func (a *Application) finishSave(s *session.Session, exportPath string) error {
+	var errs []error
+	if exportPath != "" {
+		if err := a.writeExport(export.ForPath(exportPath, a.cfg.Export), s, exportPath); err != nil {
+			errs = append(errs, fmt.Errorf("session saved but failed to export it: %w", err))
+		}
+	}
+
+	for _, format := range a.cfg.ExportFormats {
+		if a.cfg.Key != nil {
+			// the exports would leave the encrypted sessions in plaintext
+			break
+		}
+
+		exporter, err := a.Exporter(format)
+		if err == nil {
+			_, err = a.exportSession(s, exporter)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("session saved but failed to export it as %s: %w", format, err))
+		}
+	}
+
+	if err := a.indexSession(s); err != nil {
+		errs = append(errs, fmt.Errorf("session saved but failed to update the search index: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+// uniquePath appends a counter to base until base+ext does not exist.
+func uniquePath(base, ext string) string {
+	path := base + ext
+	for i := 1; ; i++ {
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			return path
+		}
+		path = fmt.Sprintf("%s-%d%s", base, i, ext)
+	}
 }
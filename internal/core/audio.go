@@ -0,0 +1,242 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/tuanta7/ekko/internal/audio"
+	"github.com/tuanta7/ekko/internal/encrypt"
+	"github.com/tuanta7/ekko/internal/session"
+)
+
+const audioDirName = "audio"
+
+type retainedChunk struct {
+	seq  int
+	path string
+}
+
+// retainAudio keeps a transcribed chunk for archiving at the end of the session.
+func (a *Application) retainAudio(seq int, path string) {
+	a.sessionMu.Lock()
+	defer a.sessionMu.Unlock()
+	a.retained = append(a.retained, retainedChunk{seq: seq, path: path})
+}
+
+// archiveAudio concatenates the retained chunks into a single compressed file next to
+// the transcripts, sealed when there is a key, and records the chunk offsets in the
+// manifest. It must only be called once the workers have finished, before the session
+// directory is removed.
+func (a *Application) archiveAudio(workDir string) error {
+	a.sessionMu.Lock()
+	retained := a.retained
+	id := a.session.Manifest.ID
+	a.sessionMu.Unlock()
+
+	if len(retained) == 0 {
+		return nil
+	}
+
+	sort.Slice(retained, func(i, j int) bool {
+		return retained[i].seq < retained[j].seq
+	})
+
+	info := &session.Audio{
+		File:   filepath.Join(audioDirName, id+a.cfg.AudioFormat.Ext()),
+		Format: string(a.cfg.AudioFormat),
+	}
+
+	inputs := make([]string, 0, len(retained))
+	var offset time.Duration
+	for _, rc := range retained {
+		d, err := audio.Duration(rc.path)
+		if err != nil {
+			continue // an empty or truncated chunk, e.g. cut short by stop
+		}
+
+		inputs = append(inputs, rc.path)
+		info.Segments = append(info.Segments, session.AudioSegment{
+			Seq:        rc.seq,
+			OffsetMS:   offset.Milliseconds(),
+			DurationMS: d.Milliseconds(),
+		})
+		offset += d
+	}
+
+	if len(inputs) == 0 {
+		return nil
+	}
+
+	// the session context is canceled by now, encoding must still complete
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	output := filepath.Join(a.cfg.OutputDir, info.File)
+	if err := a.encodeAudio(ctx, inputs, output, workDir); err != nil {
+		return fmt.Errorf("failed to archive audio: %w", err)
+	}
+
+	a.sessionMu.Lock()
+	defer a.sessionMu.Unlock()
+	a.session.Manifest.Audio = info
+	if a.saved {
+		return nil // the journal is gone, saveLate saves the recording
+	}
+	return a.journal.AppendManifest(a.session.Manifest)
+}
+
+// audioPath returns the path of a retained recording.
+func (a *Application) audioPath(info *session.Audio) string {
+	if filepath.IsAbs(info.File) {
+		return info.File
+	}
+	return filepath.Join(a.cfg.OutputDir, info.File)
+}
+
+// encodeAudio encodes the chunks into the recording at output, readable by its owner
+// only. With a key, the recording is encoded in the session directory and only its
+// sealed version is written next to the transcripts.
+func (a *Application) encodeAudio(ctx context.Context, inputs []string, output, workDir string) error {
+	if a.cfg.Key == nil {
+		if err := audio.Concat(ctx, inputs, output, a.cfg.AudioFormat); err != nil {
+			return err
+		}
+		return os.Chmod(output, 0600)
+	}
+
+	plain := filepath.Join(workDir, "recording"+a.cfg.AudioFormat.Ext())
+	if err := audio.Concat(ctx, inputs, plain, a.cfg.AudioFormat); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(output), 0700); err != nil {
+		return err
+	}
+	return a.cfg.Key.SealFile(plain, output)
+}
+
+// openAudio returns the path of a recording ffmpeg can read, a plaintext copy in the
+// session directory when it is sealed.
+func (a *Application) openAudio(recording, workDir string) (string, error) {
+	sealed, err := encrypt.IsSealedFile(recording)
+	if err != nil || !sealed {
+		return recording, err
+	}
+	if a.cfg.Key == nil {
+		return "", encrypt.ErrLocked
+	}
+
+	plain := filepath.Join(workDir, "recording"+filepath.Ext(recording))
+	if err = a.cfg.Key.OpenFile(recording, plain); err != nil {
+		return "", fmt.Errorf("failed to decrypt the retained audio: %w", err)
+	}
+	return plain, nil
+}
+
+// SealAudio seals the retained recordings archived before the encryption was enabled
+// and returns how many were sealed. Without a key it does nothing.
+func (a *Application) SealAudio() (int, error) {
+	if a.cfg.Key == nil {
+		return 0, nil
+	}
+
+	dir := filepath.Join(a.cfg.OutputDir, audioDirName)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	var errs []error
+	sealed := 0
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if entry.IsDir() {
+			continue
+		}
+		if ok, err := encrypt.IsSealedFile(path); err != nil || ok {
+			continue
+		}
+
+		if err = a.cfg.Key.SealFile(path, path); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		sealed++
+	}
+
+	return sealed, errors.Join(errs...)
+}
+
+// PruneAudio deletes retained recordings older than the configured retention and
+// returns how many were removed. The saved sessions referencing a removed recording no
+// longer reference it. A zero retention keeps recordings forever.
+func (a *Application) PruneAudio() (int, error) {
+	if a.cfg.AudioRetention <= 0 {
+		return 0, nil
+	}
+
+	dir := filepath.Join(a.cfg.OutputDir, audioDirName)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	// the sessions are listed first, a recording is only removed when its sessions
+	// can be updated
+	sessions, err := a.store.List()
+	if err != nil {
+		return 0, err
+	}
+	refs := make(map[string][]string) // recording path to the sessions referencing it
+	for _, e := range sessions {
+		if e.Manifest.Audio != nil {
+			path := filepath.Clean(a.audioPath(e.Manifest.Audio))
+			refs[path] = append(refs[path], e.Manifest.ID)
+		}
+	}
+
+	var errs []error
+	removed := 0
+	cutoff := time.Now().Add(-a.cfg.AudioRetention)
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || entry.IsDir() || info.ModTime().After(cutoff) {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		if err = os.Remove(path); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		removed++
+
+		for _, id := range refs[path] {
+			if err = a.forgetAudio(id); err != nil {
+				errs = append(errs, fmt.Errorf("session %s: %w", id, err))
+			}
+		}
+	}
+
+	return removed, errors.Join(errs...)
+}
+
+// forgetAudio removes the reference to the pruned recording from a saved session.
+func (a *Application) forgetAudio(id string) error {
+	s, err := a.store.Load(id)
+	if err != nil {
+		return err
+	}
+	s.Manifest.Audio = nil
+	return a.store.Save(s)
+}
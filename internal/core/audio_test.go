@@ -0,0 +1,144 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/tuanta7/ekko/internal/session"
+)
+
+// memStore keeps the sessions in memory.
+type memStore struct {
+	sessions map[string]*session.Session
+}
+
+func newMemStore(sessions ...*session.Session) *memStore {
+	st := &memStore{sessions: make(map[string]*session.Session)}
+	for _, s := range sessions {
+		_ = st.Save(s)
+	}
+	return st
+}
+
+func (st *memStore) Save(s *session.Session) error {
+	st.sessions[s.Manifest.ID] = s.Clone()
+	return nil
+}
+
+func (st *memStore) Load(id string) (*session.Session, error) {
+	s, ok := st.sessions[id]
+	if !ok {
+		return nil, session.ErrNotFound
+	}
+	return s.Clone(), nil
+}
+
+func (st *memStore) List() ([]session.Entry, error) {
+	var entries []session.Entry
+	for _, s := range st.sessions {
+		entries = append(entries, session.Entry{Manifest: s.Clone().Manifest})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Manifest.ID > entries[j].Manifest.ID })
+	return entries, nil
+}
+
+func (st *memStore) Delete(id string) error {
+	if _, ok := st.sessions[id]; !ok {
+		return session.ErrNotFound
+	}
+	delete(st.sessions, id)
+	return nil
+}
+
+// withAudio returns a session referencing the recording file, relative to the output directory.
+func withAudio(id, file string) *session.Session {
+	s := session.New()
+	s.Manifest.ID = id
+	s.Append(session.Chunk{Seq: 1, Text: "let us plan the budget"})
+	if file != "" {
+		s.Manifest.Audio = &session.Audio{File: file, Format: "opus", Segments: []session.AudioSegment{{Seq: 1, DurationMS: 10_000}}}
+	}
+	return s
+}
+
+func writeRecording(t *testing.T, dir, name string, age time.Duration) {
+	t.Helper()
+	path := filepath.Join(dir, audioDirName, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("OggS"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	at := time.Now().Add(-age)
+	if err := os.Chtimes(path, at, at); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPruneAudio(t *testing.T) {
+	dir := t.TempDir()
+	writeRecording(t, dir, "old.opus", 48*time.Hour)
+	writeRecording(t, dir, "new.opus", time.Hour)
+
+	st := newMemStore(
+		withAudio("old", "audio/old.opus"),
+		// a revision shares the recording of the session it was transcribed from
+		withAudio("old-revision", "audio/old.opus"),
+		withAudio("new", "audio/new.opus"),
+		withAudio("none", ""),
+	)
+	app := NewApplication(nil, nil, st, Config{OutputDir: dir, AudioRetention: 24 * time.Hour})
+
+	n, err := app.PruneAudio()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("pruned %d recordings, want 1", n)
+	}
+
+	if _, err = os.Stat(filepath.Join(dir, audioDirName, "old.opus")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("the old recording is left: %v", err)
+	}
+	if _, err = os.Stat(filepath.Join(dir, audioDirName, "new.opus")); err != nil {
+		t.Errorf("the new recording was removed: %v", err)
+	}
+
+	for id, want := range map[string]bool{"old": false, "old-revision": false, "new": true, "none": false} {
+		s, err := st.Load(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := s.Manifest.Audio != nil; got != want {
+			t.Errorf("session %s references a recording: %v, want %v", id, got, want)
+		}
+		if len(s.Chunks) != 1 {
+			t.Errorf("session %s lost its transcript", id)
+		}
+	}
+
+	// the sessions of a pruned recording have no audio to transcribe again
+	if _, err = app.Retranscribe(context.Background(), "old", nil); !errors.Is(err, ErrNoAudio) {
+		t.Errorf("Retranscribe = %v, want ErrNoAudio", err)
+	}
+}
+
+func TestPruneAudioKeepsForever(t *testing.T) {
+	dir := t.TempDir()
+	writeRecording(t, dir, "old.opus", 365*24*time.Hour)
+	st := newMemStore(withAudio("old", "audio/old.opus"))
+
+	app := NewApplication(nil, nil, st, Config{OutputDir: dir})
+	if n, err := app.PruneAudio(); err != nil || n != 0 {
+		t.Errorf("PruneAudio = %d, %v, want nothing pruned", n, err)
+	}
+	if s, _ := st.Load("old"); s.Manifest.Audio == nil {
+		t.Error("the session lost its recording")
+	}
+}
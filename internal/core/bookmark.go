@@ -0,0 +1,31 @@
+package core
+
+import (
+	"strings"
+	"time"
+
+	"github.com/tuanta7/ekko/internal/session"
+)
+
+// AddBookmark flags the moment at of the session being recorded, with an optional note.
+// The bookmark is placed in the transcript timeline after the chunk recorded at that
+// moment.
+func (a *Application) AddBookmark(at time.Time, note string) (session.Bookmark, error) {
+	if !a.active() {
+		return session.Bookmark{}, ErrNoSession
+	}
+
+	b := session.Bookmark{
+		OffsetMS:  max(at.Sub(a.startedAt).Milliseconds(), 0),
+		Timestamp: at,
+		Note:      strings.TrimSpace(a.redact(note)),
+	}
+
+	a.sessionMu.Lock()
+	defer a.sessionMu.Unlock()
+	if a.saved {
+		return session.Bookmark{}, ErrNoSession // stopped since the check
+	}
+	a.session.AddBookmark(b)
+	return b, a.journal.AppendBookmark(b)
+}
@@ -0,0 +1,74 @@
+package core
+
+import (
+	"context"
+	"sync"
+)
+
+// subscriberBuffer is how many events a subscriber may lag behind before events are
+// dropped for it.
+const subscriberBuffer = 64
+
+// broadcaster fans the events of every session out to the subscribers.
+type broadcaster struct {
+	mu   sync.Mutex
+	subs map[chan Event]struct{}
+}
+
+// Subscribe returns a channel receiving the events of the current and all following
+// sessions, until the returned function is called. Unlike the stream returned by
+// Start, a subscriber never slows a session down: events are dropped when it does
+// not keep up.
+func (a *Application) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+
+	b := &a.broadcaster
+	b.mu.Lock()
+	if b.subs == nil {
+		b.subs = make(map[chan Event]struct{})
+	}
+	b.subs[ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, ch)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+func (b *broadcaster) publish(ev Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subs {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+// dispatch forwards the events of a session to its stream and to the subscribers.
+// The stream is closed after the last event.
+func (a *Application) dispatch(ctx context.Context, events <-chan Event, stream chan<- Event) {
+	defer close(stream)
+
+	for ev := range events {
+		a.broadcaster.publish(ev)
+
+		select {
+		case stream <- ev:
+		default:
+			// the reader is behind, wait unless the session is over
+			select {
+			case stream <- ev:
+			case <-ctx.Done():
+			}
+		}
+	}
+}
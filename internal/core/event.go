@@ -0,0 +1,95 @@
+package core
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/tuanta7/ekko/internal/session"
+)
+
+type EventType int
+
+const (
+	// EventPartial carries text as it is produced by the transcriber, before the chunk is complete.
+	EventPartial EventType = iota
+	// EventChunk carries a finalized transcription chunk.
+	EventChunk
+	// EventError reports a failure; it never carries transcript text.
+	EventError
+	// EventStatus reports a change in session state, e.g. recording started.
+	EventStatus
+	// EventSessionEnd is the last event sent before the stream is closed.
+	EventSessionEnd
+	// EventSessionStart is the first event of a session, its text is the session ID.
+	EventSessionStart
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventPartial:
+		return "partial"
+	case EventChunk:
+		return "chunk"
+	case EventError:
+		return "error"
+	case EventStatus:
+		return "status"
+	case EventSessionEnd:
+		return "session_end"
+	case EventSessionStart:
+		return "session_start"
+	default:
+		return "unknown"
+	}
+}
+
+type Event struct {
+	Type  EventType
+	Time  time.Time
+	Text  string
+	Chunk *session.Chunk
+	Err   error
+}
+
+// MarshalJSON encodes the event for the JSON lines output and the event feed of the server.
+func (e Event) MarshalJSON() ([]byte, error) {
+	v := struct {
+		Type  string         `json:"type"`
+		Time  time.Time      `json:"time"`
+		Text  string         `json:"text,omitempty"`
+		Chunk *session.Chunk `json:"chunk,omitempty"`
+		Error string         `json:"error,omitempty"`
+	}{
+		Type:  e.Type.String(),
+		Time:  e.Time,
+		Text:  e.Text,
+		Chunk: e.Chunk,
+	}
+	if e.Err != nil {
+		v.Error = e.Err.Error()
+	}
+	return json.Marshal(v)
+}
+
+func newEvent(t EventType) Event {
+	return Event{Type: t, Time: time.Now()}
+}
+
+// emit delivers an event to the stream, giving up when the session is canceled.
+func (a *Application) emit(stream chan<- Event, ev Event) error {
+	select {
+	case stream <- ev:
+		return nil
+	case <-a.ctx.Done():
+		return a.ctx.Err()
+	}
+}
+
+// tryEmit delivers an event without blocking. It is used for events that may be
+// produced after the consumer has stopped reading, e.g. during shutdown.
+func tryEmit(stream chan<- Event, ev Event) {
+	select {
+	case stream <- ev:
+	default:
+	}
+}
@@ -0,0 +1,53 @@
+package core
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/tuanta7/ekko/internal/session"
+)
+
+// DefaultFilenameTemplate keeps the historical transcript-<date>-<time> naming.
+const DefaultFilenameTemplate = "transcript-{date}-{time}"
+
+var (
+	unsafeFilenameRE = regexp.MustCompile(`[^\pL\pN._-]+`)
+	separatorRunRE   = regexp.MustCompile(`[-_.]{2,}`)
+)
+
+// RenderFilename expands a filename template for the session. Supported placeholders
+// are {date}, {time}, {id}, {title}, {backend} and {model}. The result has no extension.
+func RenderFilename(template string, m session.Manifest) string {
+	if template == "" {
+		template = DefaultFilenameTemplate
+	}
+
+	r := strings.NewReplacer(
+		"{date}", m.StartedAt.Format("20060102"),
+		"{time}", m.StartedAt.Format("150405"),
+		"{id}", m.ID,
+		"{title}", slugify(m.Title),
+		"{backend}", slugify(m.Backend),
+		"{model}", slugify(m.Model),
+	)
+
+	name := sanitizeFilename(r.Replace(template))
+	if name == "" {
+		return m.ID
+	}
+
+	return name
+}
+
+func slugify(s string) string {
+	return sanitizeFilename(strings.ToLower(s))
+}
+
+func sanitizeFilename(s string) string {
+	s = unsafeFilenameRE.ReplaceAllString(strings.TrimSpace(s), "-")
+	// empty placeholders leave separators behind, e.g. "transcript--standup"
+	s = separatorRunRE.ReplaceAllStringFunc(s, func(run string) string {
+		return run[:1]
+	})
+	return strings.Trim(s, "-_.")
+}
@@ -0,0 +1,57 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tuanta7/ekko/internal/session"
+)
+
+// DefaultFormatTimeout bounds how long formatting a chunk may take, e.g. waiting for a
+// language model.
+const DefaultFormatTimeout = 30 * time.Second
+
+// DefaultParagraphGap is the gap between two chunks that starts a paragraph.
+const DefaultParagraphGap = 2 * time.Second
+
+// Formatter restores the punctuation and capitalization of the transcribed chunks, see
+// the format package.
+type Formatter interface {
+	Format(ctx context.Context, prev, text string) (string, error)
+}
+
+// paragraph marks whether the chunk starts a paragraph of s, the session it is appended
+// to, and returns the text the chunk follows in its paragraph.
+func (a *Application) paragraph(s *session.Session, c *session.Chunk) string {
+	if a.cfg.Formatter == nil {
+		return ""
+	}
+
+	c.Paragraph = s.StartsParagraph(*c, a.cfg.ParagraphGap)
+	if c.Paragraph {
+		return ""
+	}
+	return s.Chunks[len(s.Chunks)-1].Text
+}
+
+// formatChunk formats a transcribed chunk, keeping what the transcriber wrote in
+// RawText. prev is the text returned by paragraph. On error the chunk is left raw.
+func (a *Application) formatChunk(ctx context.Context, c *session.Chunk, prev string) error {
+	if a.cfg.Formatter == nil || c.Error != "" || c.Text == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultFormatTimeout)
+	defer cancel()
+
+	text, err := a.cfg.Formatter.Format(ctx, prev, c.Text)
+	if err != nil {
+		return fmt.Errorf("failed to format chunk %d: %w", c.Seq, err)
+	}
+
+	if text != c.Text {
+		c.RawText, c.Text = c.Text, text
+	}
+	return nil
+}
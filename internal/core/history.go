@@ -0,0 +1,139 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/tuanta7/ekko/internal/export"
+	"github.com/tuanta7/ekko/internal/session"
+)
+
+// UpdateSession applies fn to a saved session and saves it back, e.g. to rename or tag it.
+func (a *Application) UpdateSession(id string, fn func(s *session.Session)) error {
+	s, err := a.store.Load(id)
+	if err != nil {
+		return err
+	}
+
+	fn(s)
+	// the title and the tags are edited as typed
+	s.Manifest.Title = a.redact(s.Manifest.Title)
+	s.Manifest.Tags = a.redactTags(s.Manifest.Tags)
+
+	if err = a.store.Save(s); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	// the configured export formats were written when the session was saved first
+	if err = a.indexSession(s); err != nil {
+		return fmt.Errorf("session saved but failed to update the search index: %w", err)
+	}
+	return nil
+}
+
+// DeleteSession deletes a saved session, and its retained audio unless another
+// revision of the session still refers to it.
+func (a *Application) DeleteSession(id string) error {
+	s, err := a.store.Load(id)
+	if err != nil {
+		return err
+	}
+
+	if err = a.store.Delete(id); err != nil {
+		return err
+	}
+
+	if s.Manifest.Audio == nil {
+		return nil
+	}
+
+	recording := a.audioPath(s.Manifest.Audio)
+	if a.audioInUse(recording) {
+		return nil
+	}
+
+	if err = os.Remove(recording); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("session deleted but failed to delete audio: %w", err)
+	}
+
+	return nil
+}
+
+// audioInUse reports whether a saved session refers to the recording.
+func (a *Application) audioInUse(recording string) bool {
+	entries, err := a.store.List()
+	if err != nil {
+		return true // keep the audio when in doubt
+	}
+
+	for _, e := range entries {
+		if e.Manifest.Audio != nil && same(a.audioPath(e.Manifest.Audio), recording) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func same(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
+
+// ExportSession writes a saved session in the given format to the exports directory
+// under the output directory, named after the filename template, and returns the path
+// of the export.
+func (a *Application) ExportSession(id, format string) (string, error) {
+	exporter, err := a.Exporter(format)
+	if err != nil {
+		return "", err
+	}
+
+	s, err := a.store.Load(id)
+	if err != nil {
+		return "", err
+	}
+
+	return a.exportSession(s, exporter)
+}
+
+// Exporter returns the exporter of the format, with the configured export options.
+func (a *Application) Exporter(format string) (export.Exporter, error) {
+	return export.Get(format, a.cfg.Export)
+}
+
+func (a *Application) exportSession(s *session.Session, exporter export.Exporter) (string, error) {
+	dir := filepath.Join(a.cfg.OutputDir, "exports")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create exports directory: %w", err)
+	}
+
+	filename := uniquePath(filepath.Join(dir, RenderFilename(a.cfg.FilenameTemplate, s.Manifest)), exporter.Ext())
+	if err := a.writeExport(exporter, s, filename); err != nil {
+		return "", err
+	}
+
+	return filename, nil
+}
+
+func (a *Application) writeExport(exporter export.Exporter, s *session.Session, filename string) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0700); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	// the exports asked for are in plaintext even when the sessions are encrypted
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	if err = exporter.Export(f, a.redactSession(s)); err != nil {
+		_ = f.Close()
+		_ = os.Remove(filename)
+		return fmt.Errorf("failed to export session: %w", err)
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,105 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tuanta7/ekko/internal/session"
+)
+
+// ImportSessions stores the sessions saved as JSON files by earlier versions, e.g. the
+// transcript-*.json files. Each path is a file, or a directory whose JSON files are
+// imported, skipping the ones that are not sessions. Sessions already in the store are
+// left alone, so importing again is harmless. It returns the number of imported sessions
+// and the skipped files.
+func (a *Application) ImportSessions(paths ...string) (int, []string, error) {
+	entries, err := a.store.List()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	known := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		known[e.Manifest.ID] = true
+	}
+
+	var errs []error
+	var skipped []string
+	imported := 0
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		files := []string{path}
+		if info.IsDir() {
+			if files, err = filepath.Glob(filepath.Join(path, "*.json")); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		}
+
+		for _, file := range files {
+			s, err := session.Load(file)
+			if err != nil {
+				// other JSON files may sit in a directory
+				if info.IsDir() && errors.Is(err, session.ErrNotSession) {
+					skipped = append(skipped, file)
+				} else {
+					errs = append(errs, err)
+				}
+				continue
+			}
+
+			if known[s.Manifest.ID] {
+				continue
+			}
+
+			if s.Manifest.Audio != nil {
+				s.Manifest.Audio.File = a.importedAudio(file, s.Manifest.Audio.File)
+			}
+			s = a.redactSession(s)
+
+			if err = a.store.Save(s); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", file, err))
+				continue
+			}
+			known[s.Manifest.ID] = true
+			imported++
+
+			if err = a.indexSession(s); err != nil {
+				errs = append(errs, fmt.Errorf("%s imported but failed to update the search index: %w", file, err))
+			}
+		}
+	}
+
+	return imported, skipped, errors.Join(errs...)
+}
+
+// importedAudio rewrites the audio path of an imported session, which was relative to
+// its transcript, relative to the output directory, or absolute when it is elsewhere.
+func (a *Application) importedAudio(transcript, file string) string {
+	if filepath.IsAbs(file) {
+		return file
+	}
+
+	path, err := filepath.Abs(filepath.Join(filepath.Dir(transcript), file))
+	if err != nil {
+		return file
+	}
+
+	outputDir, err := filepath.Abs(a.cfg.OutputDir)
+	if err != nil {
+		return path
+	}
+
+	if rel, err := filepath.Rel(outputDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
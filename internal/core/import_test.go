@@ -0,0 +1,59 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/tuanta7/ekko/internal/session"
+)
+
+func TestImportSessions(t *testing.T) {
+	dir := t.TempDir()
+	s := session.New()
+	s.Manifest.Title = "Budget"
+	s.Append(session.Chunk{Seq: 1, Text: "let us plan the budget"})
+	data, err := s.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{
+		"session.json":                    string(data),
+		"transcript-20251019-100000.json": `{"1760868005": {"timestamp": 1760868000, "text": "let us plan", "error": {}}}`,
+		"settings.json":                   `{"theme": "dark", "editor": {"font": 12}}`,
+		"list.json":                       `[1, 2]`,
+		"notes.txt":                       "not JSON",
+	}
+	for name, content := range files {
+		if err = os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	st := newMemStore()
+	app := NewApplication(nil, nil, st, Config{OutputDir: dir, WorkDir: t.TempDir()})
+
+	n, skipped, err := app.ImportSessions(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 || len(st.sessions) != 2 {
+		t.Errorf("imported %d sessions, stored %d, want 2", n, len(st.sessions))
+	}
+	sort.Strings(skipped)
+	if len(skipped) != 2 || filepath.Base(skipped[0]) != "list.json" || filepath.Base(skipped[1]) != "settings.json" {
+		t.Errorf("skipped %q, want list.json and settings.json", skipped)
+	}
+
+	// importing again is harmless
+	if n, _, err = app.ImportSessions(dir); err != nil || n != 0 {
+		t.Errorf("second import = %d, %v, want nothing imported", n, err)
+	}
+
+	// a file named explicitly must be a session
+	if _, _, err = app.ImportSessions(filepath.Join(dir, "settings.json")); err == nil {
+		t.Error("imported a settings file named explicitly")
+	}
+}
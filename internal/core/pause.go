@@ -0,0 +1,133 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/tuanta7/ekko/internal/session"
+)
+
+var (
+	ErrPaused    = errors.New("session already paused")
+	ErrNotPaused = errors.New("session not paused")
+)
+
+// Pause suspends the recording without ending the session. The chunk being recorded
+// is cut short and transcribed, the transcriber context is kept for the resumed part.
+func (a *Application) Pause() error {
+	a.pauseMu.Lock()
+	defer a.pauseMu.Unlock()
+
+	// checked under pauseMu, Stop ends the pause in progress once it took the session
+	if !a.active() {
+		return ErrNoSession
+	}
+
+	if a.paused {
+		return ErrPaused
+	}
+
+	a.paused = true
+	a.resumed = make(chan struct{})
+	a.pause = session.Pause{StartMS: time.Since(a.startedAt).Milliseconds()}
+	if a.cancelChunk != nil {
+		a.cancelChunk()
+	}
+
+	return a.setPause(a.pause)
+}
+
+// Resume continues the recording of a paused session, the pause is recorded as a gap
+// in the transcript timeline.
+func (a *Application) Resume() error {
+	a.pauseMu.Lock()
+	defer a.pauseMu.Unlock()
+
+	if !a.active() {
+		return ErrNoSession
+	}
+
+	if !a.paused {
+		return ErrNotPaused
+	}
+
+	return a.endPause()
+}
+
+func (a *Application) IsPaused() bool {
+	a.pauseMu.Lock()
+	defer a.pauseMu.Unlock()
+	return a.paused
+}
+
+// active reports whether a session is recording and not being stopped.
+func (a *Application) active() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.isRunning && a.unsaved
+}
+
+// endPause closes the pause in progress, the caller holds pauseMu.
+func (a *Application) endPause() error {
+	a.paused = false
+	close(a.resumed)
+	a.pause.EndMS = time.Since(a.startedAt).Milliseconds()
+	return a.setPause(a.pause)
+}
+
+func (a *Application) setPause(p session.Pause) error {
+	a.sessionMu.Lock()
+	defer a.sessionMu.Unlock()
+	a.session.SetPause(p)
+	if a.saved {
+		return nil // the journal is gone, saveLate saves the pause
+	}
+	return a.journal.AppendPause(p)
+}
+
+// chunkContext returns the context of the next chunk, canceled by Pause. It returns
+// false when the session is paused.
+func (a *Application) chunkContext() (context.Context, context.CancelFunc, bool) {
+	a.pauseMu.Lock()
+	defer a.pauseMu.Unlock()
+
+	if a.paused {
+		return nil, nil, false
+	}
+
+	ctx, cancel := context.WithCancel(a.recordCtx)
+	a.cancelChunk = cancel
+	return ctx, cancel, true
+}
+
+// waitWhilePaused blocks until the session is resumed or stopped.
+func (a *Application) waitWhilePaused(stream chan<- Event, source string) error {
+	for {
+		a.pauseMu.Lock()
+		paused, resumed := a.paused, a.resumed
+		a.pauseMu.Unlock()
+
+		if !paused {
+			return nil
+		}
+
+		status := newEvent(EventStatus)
+		status.Text = "Paused"
+		if err := a.emit(stream, status); err != nil {
+			return err
+		}
+
+		select {
+		case <-resumed:
+			status := newEvent(EventStatus)
+			status.Text = fmt.Sprintf("Recording from %s", source)
+			if err := a.emit(stream, status); err != nil {
+				return err
+			}
+		case <-a.recordCtx.Done():
+			return a.recordCtx.Err()
+		}
+	}
+}
@@ -2,38 +2,68 @@ package core
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/tuanta7/ekko/pkg/queue"
 )
 
-// record continuously records audio and enqueues for transcription
-func (a *Application) record(duration time.Duration) error {
-	source, err := a.recorder.GetSource(a.ctx)
+// record continuously records audio and enqueues for transcription, until the
+// session is stopped
+func (a *Application) record(stream chan<- Event, duration time.Duration) error {
+	defer a.queue.Close()
+
+	source, err := a.recorder.GetSource(a.recordCtx)
 	if err != nil {
+		if a.recordCtx.Err() != nil {
+			return nil
+		}
 		return fmt.Errorf("failed to get audio source: %w", err)
 	}
 
-	defer a.queue.Close()
+	if err := a.setSource(source); err != nil {
+		return err
+	}
+
+	status := newEvent(EventStatus)
+	status.Text = fmt.Sprintf("Recording from %s", source)
+	if err := a.emit(stream, status); err != nil {
+		return err
+	}
 
 	for {
-		select {
-		case <-a.ctx.Done():
-			return a.ctx.Err()
-		default:
-			currentCount := a.counter.Add(1)
-			fileName := fmt.Sprintf(".tmp/audio-%d.wav", currentCount)
-
-			if err := a.recorder.Record(a.ctx, duration, source, fileName); err != nil {
-				if a.ctx.Err() != nil {
-					return a.ctx.Err()
-				}
-				return fmt.Errorf("recording failed: %w", err)
+		if err := a.waitWhilePaused(stream, source); err != nil {
+			if a.recordCtx.Err() != nil {
+				return nil
 			}
+			return err
+		}
 
+		chunkCtx, cancelChunk, ok := a.chunkContext()
+		if !ok {
+			continue // paused in the meantime
+		}
+
+		currentCount := a.counter.Add(1)
+		fileName := filepath.Join(a.workDir, fmt.Sprintf("audio-%d.wav", currentCount))
+		offset := time.Since(a.startedAt) // monotonic, unaffected by wall clock changes
+
+		err := a.recorder.Record(chunkCtx, duration, source, fileName)
+		cutShort := chunkCtx.Err() != nil // paused or stopped
+		cancelChunk()
+		if err != nil && !cutShort {
+			return fmt.Errorf("recording failed: %w", err)
+		}
+
+		// the chunk cut short by Pause or Stop is transcribed as well
+		if !cutShort || hasAudio(fileName) {
+			// enqueued with the session context, the chunk must not be lost when stopping
 			if err := a.queue.Enqueue(a.ctx, &queue.Message{
 				Timestamp: time.Now(),
 				FileName:  fileName,
+				Sequence:  currentCount,
+				Offset:    offset,
 			}); err != nil {
 				if a.ctx.Err() != nil {
 					return a.ctx.Err()
@@ -41,5 +71,17 @@ func (a *Application) record(duration time.Duration) error {
 				return err
 			}
 		}
+
+		if a.recordCtx.Err() != nil {
+			return nil
+		}
 	}
 }
+
+// hasAudio reports whether a WAV file holds more than its header.
+func hasAudio(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Size() > wavHeaderSize
+}
+
+const wavHeaderSize = 44
@@ -0,0 +1,88 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/tuanta7/ekko/internal/encrypt"
+	"github.com/tuanta7/ekko/internal/session"
+)
+
+// InterruptedSession is a session whose journal was left behind, because the
+// application crashed or was killed before the transcript was saved.
+type InterruptedSession struct {
+	JournalPath string
+	Manifest    session.Manifest
+	Chunks      int
+}
+
+// UnreadableJournal is a journal left behind that cannot be recovered as it is, because
+// it is encrypted and the sessions are not unlocked, or corrupted before its last line.
+// It is left for manual inspection.
+type UnreadableJournal struct {
+	Path string
+	Err  error
+}
+
+// Locked reports whether the journal only needs the passphrase to be recovered.
+func (j UnreadableJournal) Locked() bool {
+	return errors.Is(j.Err, encrypt.ErrLocked)
+}
+
+// FindInterruptedSessions returns the sessions that can be recovered from the journals
+// left behind, and the journals that cannot be read.
+func (a *Application) FindInterruptedSessions() ([]InterruptedSession, []UnreadableJournal, error) {
+	paths, err := session.FindJournals(a.cfg.JournalDir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var sessions []InterruptedSession
+	var unreadable []UnreadableJournal
+	for _, path := range paths {
+		s, err := session.ReadJournal(path, a.cfg.Key)
+		if err != nil {
+			unreadable = append(unreadable, UnreadableJournal{Path: path, Err: err})
+			continue
+		}
+
+		sessions = append(sessions, InterruptedSession{
+			JournalPath: path,
+			Manifest:    s.Manifest,
+			Chunks:      len(s.Chunks),
+		})
+	}
+
+	return sessions, unreadable, nil
+}
+
+// RecoverSession saves the session rebuilt from the journal, removes the journal and
+// returns the session ID.
+func (a *Application) RecoverSession(journalPath string) (string, error) {
+	s, err := session.ReadJournal(journalPath, a.cfg.Key)
+	if err != nil {
+		return "", err
+	}
+
+	if s.Manifest.EndedAt.IsZero() {
+		// the session ended with the last write to its journal
+		if info, err := os.Stat(journalPath); err == nil {
+			s.Manifest.EndedAt = info.ModTime()
+		}
+	}
+
+	if err = a.store.Save(s); err != nil {
+		return "", fmt.Errorf("failed to save session: %w", err)
+	}
+
+	if err = os.Remove(journalPath); err != nil {
+		return s.Manifest.ID, fmt.Errorf("session recovered but failed to remove journal: %w", err)
+	}
+
+	return s.Manifest.ID, a.finishSave(s, "")
+}
+
+func (a *Application) DiscardInterruptedSession(journalPath string) error {
+	return os.Remove(journalPath)
+}
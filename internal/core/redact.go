@@ -0,0 +1,74 @@
+package core
+
+import (
+	"strings"
+
+	"github.com/tuanta7/ekko/internal/session"
+)
+
+// Redactor removes the personal data from the transcript, see the redact package.
+type Redactor interface {
+	Redact(text string) string
+}
+
+func (a *Application) redact(text string) string {
+	if a.cfg.Redactor == nil || text == "" {
+		return text
+	}
+	return a.cfg.Redactor.Redact(text)
+}
+
+// redactTags redacts the tags, the ones left empty are dropped.
+func (a *Application) redactTags(tags []string) []string {
+	if a.cfg.Redactor == nil {
+		return tags
+	}
+
+	var redacted []string
+	for _, tag := range tags {
+		if tag = strings.TrimSpace(a.redact(tag)); tag != "" {
+			redacted = append(redacted, tag)
+		}
+	}
+	return redacted
+}
+
+// redactSession returns a redacted copy of s. The transcripts are redacted as they are
+// transcribed, the copy covers the sessions saved or imported before the redaction was
+// configured, or with other rules, wherever a session leaves the application: the
+// viewers, the exports, the search index and the language models.
+func (a *Application) redactSession(s *session.Session) *session.Session {
+	if a.cfg.Redactor == nil {
+		return s
+	}
+
+	r := *s
+	r.Manifest.Title = a.redact(s.Manifest.Title)
+	r.Manifest.Tags = a.redactTags(s.Manifest.Tags)
+	r.Chunks = make([]session.Chunk, len(s.Chunks))
+	for i, c := range s.Chunks {
+		c.Text, c.RawText = a.redact(c.Text), a.redact(c.RawText)
+		r.Chunks[i] = c
+	}
+
+	r.Bookmarks = nil
+	for _, b := range s.Bookmarks {
+		b.Note = a.redact(b.Note)
+		r.Bookmarks = append(r.Bookmarks, b)
+	}
+
+	if s.Summary != nil {
+		sum := *s.Summary
+		sum.Text = a.redact(sum.Text)
+		sum.Decisions, sum.ActionItems = nil, nil
+		for _, d := range s.Summary.Decisions {
+			sum.Decisions = append(sum.Decisions, a.redact(d))
+		}
+		for _, item := range s.Summary.ActionItems {
+			sum.ActionItems = append(sum.ActionItems, session.ActionItem{Task: a.redact(item.Task), Owner: a.redact(item.Owner)})
+		}
+		r.Summary = &sum
+	}
+
+	return &r
+}
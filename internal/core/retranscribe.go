@@ -0,0 +1,148 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tuanta7/ekko/internal/audio"
+	"github.com/tuanta7/ekko/internal/session"
+	"github.com/tuanta7/ekko/internal/transcriber"
+)
+
+var ErrNoAudio = errors.New("session has no retained audio")
+
+// Progress is called after each chunk with the number of chunks done.
+type Progress func(done, total int)
+
+// Retranscribe runs the retained audio of a saved session through the application's
+// transcriber and saves the result as a new revision of the session, the original is
+// left untouched. It returns the ID of the new revision.
+func (a *Application) Retranscribe(ctx context.Context, id string, progress Progress) (string, error) {
+	release, err := a.reserveTranscriber()
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	original, err := a.store.Load(id)
+	if err != nil {
+		return "", err
+	}
+
+	if original.Manifest.Audio == nil || len(original.Manifest.Audio.Segments) == 0 {
+		return "", ErrNoAudio
+	}
+
+	recording := a.audioPath(original.Manifest.Audio)
+	if _, err = os.Stat(recording); err != nil {
+		return "", fmt.Errorf("retained audio unavailable: %w", err)
+	}
+
+	workDir, err := createSessionDir(a.cfg.WorkDir)
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(workDir)
+
+	if recording, err = a.openAudio(recording, workDir); err != nil {
+		return "", fmt.Errorf("retained audio unavailable: %w", err)
+	}
+
+	if err = a.trClient.ResetContext(ctx); err != nil {
+		return "", fmt.Errorf("failed to reset transcriber context: %w", err)
+	}
+
+	revision := newRevision(original, a.trClient.Info())
+	originalChunks := make(map[int]session.Chunk, len(original.Chunks))
+	for _, c := range original.Chunks {
+		originalChunks[c.Seq] = c
+	}
+
+	segments := original.Manifest.Audio.Segments
+	for i, seg := range segments {
+		chunk, ok := originalChunks[seg.Seq]
+		if !ok {
+			// the chunk was lost from the transcript, e.g. by a crash, but not from the recording
+			chunk.OffsetMS = sessionOffset(seg.OffsetMS, original.Pauses)
+			chunk.Timestamp = original.Manifest.StartedAt.Add(chunk.Offset())
+		}
+		chunk.Seq = seg.Seq
+		chunk.Error, chunk.RawText, chunk.Paragraph = "", "", false
+
+		text, err := a.transcribeSegment(ctx, recording, workDir, seg)
+		if err != nil {
+			if ctx.Err() != nil {
+				return "", ctx.Err()
+			}
+			chunk.Error = err.Error()
+		}
+		chunk.Text = a.redact(text)
+		// a chunk that cannot be formatted is kept raw
+		_ = a.formatChunk(ctx, &chunk, a.paragraph(revision, &chunk))
+		revision.Append(chunk)
+
+		if progress != nil {
+			progress(i+1, len(segments))
+		}
+	}
+
+	revision.Manifest.TranscribedAt = time.Now()
+	return a.SaveSession(revision, "")
+}
+
+// transcribeSegment extracts a section of a recording and returns its transcript.
+func (a *Application) transcribeSegment(ctx context.Context, recording, workDir string, seg session.AudioSegment) (string, error) {
+	wavPath := filepath.Join(workDir, fmt.Sprintf("audio-%d.wav", seg.Seq))
+	defer os.Remove(wavPath)
+
+	if err := audio.Extract(ctx, recording, wavPath, seg.Offset(), seg.Duration()); err != nil {
+		return "", err
+	}
+
+	reader, err := a.trClient.Transcribe(ctx, wavPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to transcribe audio: %w", err)
+	}
+	defer reader.Close()
+
+	var b strings.Builder
+	if _, err = io.Copy(&b, reader); err != nil {
+		return b.String(), fmt.Errorf("failed to read transcript: %w", err)
+	}
+
+	return strings.ReplaceAll(b.String(), "\n", ""), nil
+}
+
+// sessionOffset estimates the offset in the session of an offset in its recording,
+// which leaves the pauses out.
+func sessionOffset(recordingMS int64, pauses []session.Pause) int64 {
+	offset := recordingMS
+	for _, p := range pauses {
+		if p.EndMS == 0 || p.StartMS > offset {
+			break
+		}
+		offset += p.EndMS - p.StartMS
+	}
+	return offset
+}
+
+func newRevision(original *session.Session, info transcriber.Info) *session.Session {
+	s := session.New()
+	s.Manifest = original.Manifest
+	s.Manifest.ID = session.NewID()
+	s.Manifest.ParentID = original.Manifest.ID
+	s.Manifest.Revision = original.Manifest.RevisionNumber() + 1
+	s.Manifest.Backend = string(info.Mode)
+	s.Manifest.Model = info.Model
+	s.Manifest.Language = info.Language
+	s.Manifest.AppVersion = Version
+	s.Pauses = original.Pauses // same recording, same gaps
+	s.Bookmarks = original.Bookmarks
+	return s
+}
@@ -0,0 +1,56 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/tuanta7/ekko/internal/session"
+)
+
+func TestReserveTranscriber(t *testing.T) {
+	app := NewApplication(nil, nil, newMemStore(), Config{OutputDir: t.TempDir()})
+
+	release, err := app.reserveTranscriber()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = app.Start(SessionOptions{}); !errors.Is(err, ErrTranscribing) {
+		t.Errorf("Start during a transcription = %v, want ErrTranscribing", err)
+	}
+	if _, err = app.reserveTranscriber(); !errors.Is(err, ErrTranscribing) {
+		t.Errorf("second reservation = %v, want ErrTranscribing", err)
+	}
+
+	release()
+	again, err := app.reserveTranscriber()
+	if err != nil {
+		t.Fatalf("reservation after release: %v", err)
+	}
+	again()
+}
+
+func TestSessionOffset(t *testing.T) {
+	pauses := []session.Pause{
+		{StartMS: 10_000, EndMS: 15_000},
+		{StartMS: 30_000, EndMS: 40_000},
+		{StartMS: 60_000}, // paused until the end
+	}
+
+	tests := []struct {
+		recording int64
+		want      int64
+	}{
+		{0, 0},
+		{9_000, 9_000},
+		{10_000, 15_000},
+		{20_000, 25_000},
+		{25_000, 40_000},
+		{50_000, 65_000},
+	}
+	for _, tt := range tests {
+		if got := sessionOffset(tt.recording, pauses); got != tt.want {
+			t.Errorf("sessionOffset(%d) = %d, want %d", tt.recording, got, tt.want)
+		}
+	}
+}
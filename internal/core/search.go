@@ -0,0 +1,80 @@
+package core
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/tuanta7/ekko/internal/search"
+	"github.com/tuanta7/ekko/internal/session"
+)
+
+// indexPath is where the search index is kept, it is rebuilt from the store when lost.
+func (a *Application) indexPath() string {
+	return filepath.Join(a.cfg.WorkDir, "search.idx")
+}
+
+// indexSession adds a saved session to the search index.
+func (a *Application) indexSession(s *session.Session) error {
+	a.indexMu.Lock()
+	defer a.indexMu.Unlock()
+
+	idx := search.Open(a.indexPath(), a.cfg.Key)
+	idx.Add(a.redactSession(s))
+	return idx.Save()
+}
+
+// Search returns the transcript chunks matching the query, best first. The index is
+// first brought up to date with the store, so sessions saved by other processes, e.g.
+// ekko serve, are found as well.
+func (a *Application) Search(query string, limit int) ([]search.Result, error) {
+	if len(search.Tokenize(query)) == 0 {
+		return nil, search.ErrEmptyQuery
+	}
+
+	a.indexMu.Lock()
+	defer a.indexMu.Unlock()
+
+	idx := search.Open(a.indexPath(), a.cfg.Key)
+	if err := a.syncIndex(idx); err != nil {
+		return nil, err
+	}
+
+	results := idx.Search(query, limit)
+	// the index may predate the redaction, until it is rebuilt by Reindex
+	for i := range results {
+		results[i].Text = a.redact(results[i].Text)
+	}
+	return results, nil
+}
+
+// Reindex rebuilds the search index from the store and returns the number of indexed
+// sessions.
+func (a *Application) Reindex() (int, error) {
+	a.indexMu.Lock()
+	defer a.indexMu.Unlock()
+
+	if err := os.Remove(a.indexPath()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return 0, err
+	}
+
+	idx := search.Open(a.indexPath(), a.cfg.Key)
+	if err := a.syncIndex(idx); err != nil {
+		return 0, err
+	}
+
+	return idx.Len(), nil
+}
+
+func (a *Application) syncIndex(idx *search.Index) error {
+	entries, err := a.store.List()
+	if err != nil {
+		return err
+	}
+
+	changed, err := idx.Sync(entries, a.LoadSession)
+	if err != nil || !changed {
+		return err
+	}
+	return idx.Save()
+}
@@ -0,0 +1,48 @@
+package core
+
+import "github.com/tuanta7/ekko/internal/session"
+
+type State string
+
+const (
+	StateIdle      State = "idle"
+	StateRecording State = "recording"
+	StatePaused    State = "paused"
+	// StateStopping is reported from Stop until the transcript is saved, and when the
+	// session ended on its own and has not been stopped yet.
+	StateStopping State = "stopping"
+)
+
+// Status describes the current session.
+type Status struct {
+	State State `json:"state"`
+	// Session is the manifest of the current session, nil when idle.
+	Session *session.Manifest `json:"session,omitempty"`
+	Chunks  int               `json:"chunks"`
+}
+
+func (a *Application) Status() Status {
+	a.mu.Lock()
+	running, unsaved := a.isRunning, a.unsaved
+	a.mu.Unlock()
+
+	var st Status
+	switch {
+	case !running && !unsaved:
+		return Status{State: StateIdle}
+	case running && unsaved && a.IsPaused():
+		st.State = StatePaused
+	case running && unsaved:
+		st.State = StateRecording
+	default:
+		st.State = StateStopping
+	}
+
+	a.sessionMu.Lock()
+	m := a.session.Manifest
+	st.Session = &m
+	st.Chunks = len(a.session.Chunks)
+	a.sessionMu.Unlock()
+
+	return st
+}
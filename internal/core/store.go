@@ -0,0 +1,62 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tuanta7/ekko/internal/session"
+)
+
+// Store keeps the saved sessions, see the store package for the SQLite implementation.
+type Store interface {
+	// Save inserts the session, or replaces the saved session with the same ID.
+	Save(s *session.Session) error
+	// Load returns the session with the given ID, or session.ErrNotFound.
+	Load(id string) (*session.Session, error)
+	// List returns the saved sessions without their transcript, most recent first.
+	List() ([]session.Entry, error)
+	// Delete deletes the session with the given ID, or returns session.ErrNotFound.
+	Delete(id string) error
+}
+
+// ListSessions returns the saved sessions, most recent first.
+func (a *Application) ListSessions() ([]session.Entry, error) {
+	return a.store.List()
+}
+
+// LoadSession returns the saved session with the given ID, redacted when a redactor is
+// configured.
+func (a *Application) LoadSession(id string) (*session.Session, error) {
+	s, err := a.store.Load(id)
+	if err != nil {
+		return nil, err
+	}
+	return a.redactSession(s), nil
+}
+
+// FindSession resolves a session ID or unique ID prefix to the session ID.
+func (a *Application) FindSession(ref string) (string, error) {
+	entries, err := a.store.List()
+	if err != nil {
+		return "", err
+	}
+
+	var matches []string
+	for _, e := range entries {
+		if e.Manifest.ID == ref {
+			return ref, nil
+		}
+		if ref != "" && strings.HasPrefix(e.Manifest.ID, ref) {
+			matches = append(matches, e.Manifest.ID)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("%w: %s", session.ErrNotFound, ref)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("ambiguous session %q matches %d sessions", ref, len(matches))
+	}
+}
@@ -0,0 +1,53 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/tuanta7/ekko/internal/session"
+)
+
+// DefaultSummaryTimeout bounds how long Summarize waits for the language model.
+const DefaultSummaryTimeout = 2 * time.Minute
+
+var ErrNoSummarizer = errors.New("no summary provider configured")
+
+// Summarizer writes the summary of a session, see the summary package for the Gemini
+// and OpenAI implementations.
+type Summarizer interface {
+	Summarize(ctx context.Context, s *session.Session) (*session.Summary, error)
+}
+
+// CanSummarize reports whether a summary provider is configured.
+func (a *Application) CanSummarize() bool {
+	return a.cfg.Summarizer != nil
+}
+
+// Summarize asks the language model for the summary, the decisions and the action items
+// of a saved session, e.g. once Stop returns, and stores it with the session, replacing
+// the previous one.
+func (a *Application) Summarize(ctx context.Context, id string) (*session.Summary, error) {
+	if a.cfg.Summarizer == nil {
+		return nil, ErrNoSummarizer
+	}
+
+	s, err := a.store.Load(id)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultSummaryTimeout)
+	defer cancel()
+
+	summary, err := a.cfg.Summarizer.Summarize(ctx, a.redactSession(s))
+	if err != nil {
+		return nil, err
+	}
+
+	// the session may have been renamed or tagged meanwhile
+	err = a.UpdateSession(id, func(s *session.Session) {
+		s.Summary = summary
+	})
+	return summary, err
+}
@@ -5,13 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"time"
 
+	"github.com/tuanta7/ekko/internal/session"
 	"github.com/tuanta7/ekko/pkg/queue"
 )
 
 // transcribe continuously dequeues and transcribes audio files
-func (a *Application) transcribe(stream chan string) error {
+func (a *Application) transcribe(stream chan<- Event) error {
 	for {
 		msg, err := a.queue.Dequeue(a.ctx)
 		if err != nil {
@@ -33,36 +33,73 @@ func (a *Application) transcribe(stream chan string) error {
 		scanner := bufio.NewScanner(reader)
 
 		for scanner.Scan() {
-			chunk := scanner.Text()
-			fullText += chunk
+			text := scanner.Text()
+			fullText += text
 
-			select {
-			case stream <- chunk:
-			case <-a.ctx.Done():
+			// the partial text cannot be redacted, a word may be cut between two partials
+			if a.cfg.Redactor != nil {
+				continue
+			}
+
+			partial := newEvent(EventPartial)
+			partial.Text = text
+			if err := a.emit(stream, partial); err != nil {
 				_ = reader.Close()
-				return a.ctx.Err()
+				return err
 			}
 		}
 
 		transcriptionErr := scanner.Err()
+		_ = reader.Close()
+		if a.cfg.KeepAudio {
+			a.retainAudio(int(msg.Sequence), msg.FileName)
+		} else {
+			_ = os.Remove(msg.FileName)
+		}
+
 		if transcriptionErr != nil {
-			errMsg := fmt.Sprintf("[Error] Failed to read transcript: %s\n", transcriptionErr.Error())
+			ev := newEvent(EventError)
+			ev.Err = fmt.Errorf("failed to read transcript: %w", transcriptionErr)
+			if err := a.emit(stream, ev); err != nil {
+				return err
+			}
+		}
 
-			select {
-			case stream <- errMsg:
-			case <-a.ctx.Done():
-				_ = reader.Close()
-				return a.ctx.Err()
+		chunk := session.Chunk{
+			Seq:       int(msg.Sequence),
+			OffsetMS:  msg.Offset.Milliseconds(),
+			Timestamp: msg.Timestamp,
+			Text:      a.redact(fullText),
+		}
+		if transcriptionErr != nil {
+			chunk.Error = transcriptionErr.Error()
+		}
+
+		a.sessionMu.Lock()
+		prev := a.paragraph(a.session, &chunk)
+		a.sessionMu.Unlock()
+		// a chunk that cannot be formatted is kept raw, as when stopping cancels the formatting
+		if err := a.formatChunk(a.ctx, &chunk, prev); err != nil && a.ctx.Err() == nil {
+			ev := newEvent(EventError)
+			ev.Err = err
+			if err := a.emit(stream, ev); err != nil {
+				return err
 			}
 		}
 
-		_ = reader.Close()
-		_ = os.Remove(msg.FileName)
+		if err := a.appendChunk(chunk); err != nil {
+			ev := newEvent(EventError)
+			ev.Err = err
+			if err := a.emit(stream, ev); err != nil {
+				return err
+			}
+		}
 
-		a.transcription.Store(time.Now().Unix(), TranscriptionChunk{
-			Timestamp: msg.Timestamp.Unix(),
-			Text:      fullText,
-			Error:     transcriptionErr,
-		})
+		ev := newEvent(EventChunk)
+		ev.Text = chunk.Text
+		ev.Chunk = &chunk
+		if err := a.emit(stream, ev); err != nil {
+			return err
+		}
 	}
 }
@@ -0,0 +1,110 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/tuanta7/ekko/internal/audio"
+	"github.com/tuanta7/ekko/internal/session"
+)
+
+// TranscribeFile transcribes an existing audio file, in any format ffmpeg can decode,
+// by splitting it into chunks of the given duration. The session is returned unsaved.
+func (a *Application) TranscribeFile(ctx context.Context, path string, opts SessionOptions, progress Progress) (*session.Session, error) {
+	release, err := a.reserveTranscriber()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	if opts.ChunkDuration <= 0 {
+		return nil, errors.New("chunk duration must be positive")
+	}
+
+	workDir, err := createSessionDir(a.cfg.WorkDir)
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(workDir)
+
+	// decode once, so the chunks are cut from a seekable file with a known length
+	decoded := filepath.Join(workDir, "input.wav")
+	if err = audio.Extract(ctx, path, decoded, 0, 0); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+
+	total, err := audio.Duration(decoded)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = a.trClient.ResetContext(ctx); err != nil {
+		return nil, fmt.Errorf("failed to reset transcriber context: %w", err)
+	}
+
+	info := a.trClient.Info()
+	s := session.New()
+	s.Manifest.Title = a.redact(opts.Title)
+	s.Manifest.Tags = a.redactTags(opts.Tags)
+	s.Manifest.Backend = string(info.Mode)
+	s.Manifest.Model = info.Model
+	s.Manifest.Language = info.Language
+	s.Manifest.Source = path
+	s.Manifest.ChunkDurationMS = opts.ChunkDuration.Milliseconds()
+	s.Manifest.AppVersion = Version
+	s.Manifest.Revision = 1
+	s.Manifest.TranscribedAt = time.Now()
+	if stat, err := os.Stat(path); err == nil {
+		// the best guess for when the recording ended
+		s.Manifest.EndedAt = stat.ModTime()
+		s.Manifest.StartedAt = stat.ModTime().Add(-total)
+	}
+
+	count := int((total + opts.ChunkDuration - 1) / opts.ChunkDuration)
+	for i := 0; i < count; i++ {
+		seg := session.AudioSegment{
+			Seq:        i + 1,
+			OffsetMS:   (time.Duration(i) * opts.ChunkDuration).Milliseconds(),
+			DurationMS: min(opts.ChunkDuration, total-time.Duration(i)*opts.ChunkDuration).Milliseconds(),
+		}
+
+		chunk := session.Chunk{
+			Seq:       seg.Seq,
+			OffsetMS:  seg.OffsetMS,
+			Timestamp: s.Manifest.StartedAt.Add(seg.Offset()),
+		}
+
+		text, err := a.transcribeSegment(ctx, decoded, workDir, seg)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			chunk.Error = err.Error()
+		}
+		chunk.Text = a.redact(text)
+		// a chunk that cannot be formatted is kept raw
+		_ = a.formatChunk(ctx, &chunk, a.paragraph(s, &chunk))
+		s.Append(chunk)
+
+		if progress != nil {
+			progress(i+1, count)
+		}
+	}
+
+	return s, nil
+}
+
+// SaveSession saves a session built outside of a live recording, e.g. by TranscribeFile,
+// and returns its ID. A non-empty path also exports the transcript there, in the format
+// of its extension, JSON by default.
+func (a *Application) SaveSession(s *session.Session, path string) (string, error) {
+	if err := a.store.Save(s); err != nil {
+		return "", fmt.Errorf("failed to save session: %w", err)
+	}
+
+	return s.Manifest.ID, a.finishSave(s, path)
+}
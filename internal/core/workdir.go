@@ -0,0 +1,102 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+const (
+	sessionDirPattern = "session-*"
+	ownerFile         = "owner.pid"
+)
+
+// createSessionDir creates a unique temporary directory for the session and records
+// the owning process, so that directories left behind by a crash can be told apart
+// from the ones used by a concurrently running instance.
+func createSessionDir(parent string) (string, error) {
+	if err := os.MkdirAll(parent, 0700); err != nil {
+		return "", fmt.Errorf("failed to create working directory: %w", err)
+	}
+
+	dir, err := os.MkdirTemp(parent, sessionDirPattern)
+	if err != nil {
+		return "", fmt.Errorf("failed to create session directory: %w", err)
+	}
+
+	pid := []byte(strconv.Itoa(os.Getpid()))
+	if err = os.WriteFile(filepath.Join(dir, ownerFile), pid, 0600); err != nil {
+		_ = os.RemoveAll(dir)
+		return "", fmt.Errorf("failed to write session owner: %w", err)
+	}
+
+	return dir, nil
+}
+
+// FindOrphanedSessionDirs returns the session directories under workDir whose owning
+// process is no longer running.
+func FindOrphanedSessionDirs(workDir string) ([]string, error) {
+	dirs, err := filepath.Glob(filepath.Join(workDir, sessionDirPattern))
+	if err != nil {
+		return nil, err
+	}
+
+	var orphans []string
+	for _, dir := range dirs {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			continue
+		}
+
+		if !ownerAlive(dir) {
+			orphans = append(orphans, dir)
+		}
+	}
+
+	return orphans, nil
+}
+
+// RemoveOrphanedSessionDirs deletes the directories left behind by crashed sessions
+// and returns how many were removed.
+func (a *Application) RemoveOrphanedSessionDirs() (int, error) {
+	orphans, err := FindOrphanedSessionDirs(a.cfg.WorkDir)
+	if err != nil {
+		return 0, err
+	}
+
+	var errs []error
+	removed := 0
+	for _, dir := range orphans {
+		if err := os.RemoveAll(dir); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		removed++
+	}
+
+	return removed, errors.Join(errs...)
+}
+
+func ownerAlive(dir string) bool {
+	data, err := os.ReadFile(filepath.Join(dir, ownerFile))
+	if err != nil {
+		// directories created before the owner file existed, or crashed before writing it
+		return false
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return false
+	}
+
+	if pid == os.Getpid() {
+		return true
+	}
+
+	// signal 0 performs the existence and permission checks without sending anything
+	err = syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
@@ -0,0 +1,127 @@
+// Package encrypt seals the saved transcripts and recordings with a key derived from a
+// passphrase. Values are sealed with AES-256-GCM, the files in segments so a recording
+// never has to fit in memory.
+package encrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+var (
+	// ErrWrongPassphrase is returned when the key does not open the sealed data.
+	ErrWrongPassphrase = errors.New("wrong passphrase")
+	// ErrLocked is returned when encrypted data is read or written without a key.
+	ErrLocked = errors.New("the sessions are encrypted, a passphrase is needed")
+)
+
+// SaltSize is the size of the salts of NewSalt.
+const SaltSize = 16
+
+// the Argon2id parameters recommended by RFC 9106 for memory constrained machines,
+// changing them changes every derived key
+const (
+	argonTime    = 3
+	argonMemory  = 64 * 1024 // KiB
+	argonThreads = 4
+)
+
+// Key seals and opens data. It is safe for concurrent use.
+type Key struct {
+	aead cipher.AEAD
+}
+
+// NewSalt returns a random salt for DeriveKey.
+func NewSalt() ([]byte, error) {
+	salt := make([]byte, SaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+// DeriveKey derives the key of a passphrase with Argon2id, it takes about a second.
+func DeriveKey(passphrase string, salt []byte) (*Key, error) {
+	if passphrase == "" {
+		return nil, errors.New("empty passphrase")
+	}
+	if len(salt) != SaltSize {
+		return nil, fmt.Errorf("invalid salt size %d", len(salt))
+	}
+
+	block, err := aes.NewCipher(argon2.IDKey([]byte(passphrase), salt, argonTime, argonMemory, argonThreads, 32))
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Key{aead: aead}, nil
+}
+
+// Seal encrypts data with a random nonce, which is prepended to the result.
+func (k *Key) Seal(data []byte) []byte {
+	nonce := make([]byte, k.aead.NonceSize(), k.aead.NonceSize()+len(data)+k.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		panic(err) // crypto/rand never fails on supported platforms
+	}
+	return k.aead.Seal(nonce, nonce, data, nil)
+}
+
+// Open decrypts data sealed by Seal.
+func (k *Key) Open(sealed []byte) ([]byte, error) {
+	if len(sealed) < k.aead.NonceSize()+k.aead.Overhead() {
+		return nil, errors.New("sealed data too short")
+	}
+
+	n := k.aead.NonceSize()
+	data, err := k.aead.Open(nil, sealed[:n], sealed[n:], nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return data, nil
+}
+
+// stringPrefix marks the sealed strings, so the values written before the encryption
+// was enabled are still read.
+const stringPrefix = "ekkoenc1:"
+
+// SealString seals a string as text, the empty string is kept empty.
+func (k *Key) SealString(s string) string {
+	if s == "" {
+		return ""
+	}
+	return stringPrefix + base64.RawStdEncoding.EncodeToString(k.Seal([]byte(s)))
+}
+
+// OpenString opens a string sealed by SealString, other strings are returned as they are.
+func (k *Key) OpenString(s string) (string, error) {
+	if !IsSealed(s) {
+		return s, nil
+	}
+
+	sealed, err := base64.RawStdEncoding.DecodeString(s[len(stringPrefix):])
+	if err != nil {
+		return "", fmt.Errorf("invalid sealed string: %w", err)
+	}
+
+	data, err := k.Open(sealed)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// IsSealed reports whether s was sealed by SealString.
+func IsSealed(s string) bool {
+	return strings.HasPrefix(s, stringPrefix)
+}
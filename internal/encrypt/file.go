@@ -0,0 +1,270 @@
+package encrypt
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// A sealed file starts with fileMagic and a random nonce prefix, followed by the
+// segments of the data sealed one by one. The nonce of a segment is the prefix, the
+// segment counter and a flag set on the last segment, so segments can neither be
+// reordered nor dropped, including at the end.
+const (
+	fileMagic       = "ekkoenc1"
+	noncePrefixSize = 7
+	segmentSize     = 64 * 1024
+)
+
+type writer struct {
+	key     *Key
+	w       io.Writer
+	prefix  []byte
+	counter uint32
+	buf     []byte
+	err     error
+}
+
+// NewWriter returns a writer sealing what is written to w. Close must be called to seal
+// the last segment, it does not close w.
+func (k *Key) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	prefix := make([]byte, noncePrefixSize)
+	if _, err := rand.Read(prefix); err != nil {
+		return nil, err
+	}
+
+	if _, err := io.WriteString(w, fileMagic); err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(prefix); err != nil {
+		return nil, err
+	}
+
+	return &writer{key: k, w: w, prefix: prefix, buf: make([]byte, 0, segmentSize)}, nil
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	n := len(p)
+	for len(p) > 0 {
+		// a full buffer is only sealed once more data comes, the last segment must
+		// not be empty unless the data is
+		if len(w.buf) == segmentSize {
+			if w.err = w.seal(false); w.err != nil {
+				return n - len(p), w.err
+			}
+		}
+
+		c := copy(w.buf[len(w.buf):segmentSize], p)
+		w.buf = w.buf[:len(w.buf)+c]
+		p = p[c:]
+	}
+
+	return n, nil
+}
+
+func (w *writer) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+	w.err = w.seal(true)
+	if w.err == nil {
+		w.err = errors.New("sealed writer closed")
+		return nil
+	}
+	return w.err
+}
+
+func (w *writer) seal(last bool) error {
+	if w.counter == ^uint32(0) {
+		return errors.New("sealed file too large")
+	}
+
+	sealed := w.key.aead.Seal(nil, segmentNonce(w.prefix, w.counter, last), w.buf, nil)
+	w.counter++
+	w.buf = w.buf[:0]
+
+	_, err := w.w.Write(sealed)
+	return err
+}
+
+func segmentNonce(prefix []byte, counter uint32, last bool) []byte {
+	nonce := make([]byte, 0, noncePrefixSize+5)
+	nonce = append(nonce, prefix...)
+	nonce = binary.BigEndian.AppendUint32(nonce, counter)
+	if last {
+		return append(nonce, 1)
+	}
+	return append(nonce, 0)
+}
+
+type reader struct {
+	key     *Key
+	r       *bufio.Reader
+	prefix  []byte
+	counter uint32
+	sealed  []byte
+	plain   []byte
+	done    bool
+}
+
+// NewReader returns a reader opening the data sealed by NewWriter from r.
+func (k *Key) NewReader(r io.Reader) (io.Reader, error) {
+	header := make([]byte, len(fileMagic)+noncePrefixSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("not a sealed file: %w", err)
+	}
+	if string(header[:len(fileMagic)]) != fileMagic {
+		return nil, errors.New("not a sealed file")
+	}
+
+	return &reader{
+		key:    k,
+		r:      bufio.NewReaderSize(r, segmentSize+k.aead.Overhead()+1),
+		prefix: header[len(fileMagic):],
+		sealed: make([]byte, segmentSize+k.aead.Overhead()),
+	}, nil
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	for len(r.plain) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.next(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+	return n, nil
+}
+
+func (r *reader) next() error {
+	n, err := io.ReadFull(r.r, r.sealed)
+	last := false
+	switch {
+	case errors.Is(err, io.EOF):
+		return fmt.Errorf("sealed file truncated: %w", io.ErrUnexpectedEOF)
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		last = true
+	case err != nil:
+		return err
+	default:
+		// a full segment is the last one when nothing follows
+		if _, err = r.r.Peek(1); errors.Is(err, io.EOF) {
+			last = true
+		} else if err != nil {
+			return err
+		}
+	}
+
+	plain, err := r.key.aead.Open(r.sealed[:0:0], segmentNonce(r.prefix, r.counter, last), r.sealed[:n], nil)
+	if err != nil {
+		return errors.New("sealed file corrupted, truncated or sealed with another key")
+	}
+
+	r.counter++
+	r.plain = plain
+	r.done = last
+	return nil
+}
+
+// IsSealedFile reports whether the file at path was sealed by SealFile.
+func IsSealedFile(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	magic := make([]byte, len(fileMagic))
+	if _, err = io.ReadFull(f, magic); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return false, nil
+		}
+		return false, err
+	}
+	return string(magic) == fileMagic, nil
+}
+
+// SealFile writes the sealed version of the file src to dst, readable by its owner only.
+// dst may be src, a file already sealed is then left as it is.
+func (k *Key) SealFile(src, dst string) error {
+	if sealed, err := IsSealedFile(src); err != nil {
+		return err
+	} else if sealed {
+		if src == dst {
+			return nil
+		}
+		return fmt.Errorf("%s is already sealed", src)
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	return writeFile(dst, func(out io.Writer) error {
+		w, err := k.NewWriter(out)
+		if err != nil {
+			return err
+		}
+		if _, err = io.Copy(w, in); err != nil {
+			return err
+		}
+		return w.Close()
+	})
+}
+
+// OpenFile writes the data of the sealed file src to dst, readable by its owner only.
+func (k *Key) OpenFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	r, err := k.NewReader(in)
+	if err != nil {
+		return fmt.Errorf("%s: %w", src, err)
+	}
+
+	return writeFile(dst, func(out io.Writer) error {
+		_, err := io.Copy(out, r)
+		return err
+	})
+}
+
+// writeFile replaces the file at path atomically with what write writes.
+func writeFile(path string, write func(w io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".sealed-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err = write(tmp); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
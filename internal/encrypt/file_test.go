@@ -0,0 +1,230 @@
+package encrypt
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const headerSize = len(fileMagic) + noncePrefixSize
+
+func testKey(t *testing.T, passphrase string) *Key {
+	t.Helper()
+	key, err := DeriveKey(passphrase, bytes.Repeat([]byte{1}, SaltSize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func seal(t *testing.T, key *Key, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := key.NewWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func open(key *Key, sealed []byte) ([]byte, error) {
+	r, err := key.NewReader(bytes.NewReader(sealed))
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func randomBytes(t *testing.T, n int) []byte {
+	t.Helper()
+	data := make([]byte, n)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// segments splits a sealed stream into its header and segments.
+func segments(key *Key, sealed []byte) ([]byte, [][]byte) {
+	size := segmentSize + key.aead.Overhead()
+	header, rest := sealed[:headerSize], sealed[headerSize:]
+	var segs [][]byte
+	for len(rest) > size {
+		segs = append(segs, rest[:size])
+		rest = rest[size:]
+	}
+	return header, append(segs, rest)
+}
+
+func join(header []byte, segs ...[]byte) []byte {
+	return bytes.Join(append([][]byte{header}, segs...), nil)
+}
+
+func TestRoundTrip(t *testing.T) {
+	key := testKey(t, "passphrase")
+
+	for _, n := range []int{0, 1, segmentSize - 1, segmentSize, segmentSize + 1, 3 * segmentSize} {
+		data := randomBytes(t, n)
+		sealed := seal(t, key, data)
+
+		got, err := open(key, sealed)
+		if err != nil {
+			t.Fatalf("%d bytes: %v", n, err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("%d bytes: opened data differs", n)
+		}
+	}
+}
+
+func TestRoundTripSmallWrites(t *testing.T) {
+	key := testKey(t, "passphrase")
+	data := randomBytes(t, 2*segmentSize+10)
+
+	var buf bytes.Buffer
+	w, err := key.NewWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < len(data); i += 1000 {
+		if _, err = w.Write(data[i:min(i+1000, len(data))]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := open(key, buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Error("opened data differs")
+	}
+}
+
+func TestTampering(t *testing.T) {
+	key := testKey(t, "passphrase")
+	sealed := seal(t, key, randomBytes(t, 3*segmentSize+100))
+	header, segs := segments(key, sealed)
+	if len(segs) != 4 {
+		t.Fatalf("got %d segments, want 4", len(segs))
+	}
+
+	tests := []struct {
+		name   string
+		sealed []byte
+	}{
+		{"truncated at a segment boundary", join(header, segs[:2]...)},
+		{"truncated in a segment", sealed[:len(sealed)-10]},
+		{"header only", header},
+		{"last segment dropped", join(header, segs[:3]...)},
+		{"middle segment dropped", join(header, segs[0], segs[2], segs[3])},
+		{"segments reordered", join(header, segs[1], segs[0], segs[2], segs[3])},
+		{"last segment moved", join(header, segs[0], segs[1], segs[3], segs[2])},
+		{"flipped bit", func() []byte {
+			b := bytes.Clone(sealed)
+			b[headerSize+5] ^= 1
+			return b
+		}()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := open(key, tt.sealed); err == nil {
+				t.Error("opened a tampered stream")
+			}
+		})
+	}
+}
+
+func TestWrongKey(t *testing.T) {
+	sealed := seal(t, testKey(t, "passphrase"), []byte("the budget is due on Friday"))
+	if _, err := open(testKey(t, "other"), sealed); err == nil {
+		t.Error("opened with the wrong key")
+	}
+}
+
+func TestNotSealed(t *testing.T) {
+	if _, err := open(testKey(t, "passphrase"), []byte("OggS plain audio")); err == nil {
+		t.Error("opened a plaintext stream")
+	}
+}
+
+func TestSealFile(t *testing.T) {
+	key := testKey(t, "passphrase")
+	dir := t.TempDir()
+	path := filepath.Join(dir, "recording.opus")
+	data := randomBytes(t, segmentSize+1)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := key.SealFile(path, path); err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := IsSealedFile(path); err != nil || !ok {
+		t.Fatalf("IsSealedFile = %v, %v, want true", ok, err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("sealed file mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	// sealing again leaves it as it is
+	if err = key.SealFile(path, path); err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := os.ReadFile(path); !bytes.Equal(again, sealed) {
+		t.Error("a sealed file was sealed again")
+	}
+
+	plain := filepath.Join(dir, "plain.opus")
+	if err = key.OpenFile(path, plain); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(plain); !bytes.Equal(got, data) {
+		t.Error("opened file differs")
+	}
+}
+
+func TestStrings(t *testing.T) {
+	key := testKey(t, "passphrase")
+
+	sealed := key.SealString("Sam sends the budget")
+	if !IsSealed(sealed) {
+		t.Fatalf("SealString = %q, not sealed", sealed)
+	}
+	if got, err := key.OpenString(sealed); err != nil || got != "Sam sends the budget" {
+		t.Errorf("OpenString = %q, %v", got, err)
+	}
+
+	if _, err := testKey(t, "other").OpenString(sealed); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("OpenString with the wrong key = %v, want ErrWrongPassphrase", err)
+	}
+
+	if got := key.SealString(""); got != "" {
+		t.Errorf("SealString(\"\") = %q, want empty", got)
+	}
+
+	// the values saved before the encryption was enabled are read as they are
+	for _, legacy := range []string{"", "let us plan the budget", "ekkoenc: not sealed"} {
+		if got, err := key.OpenString(legacy); err != nil || got != legacy {
+			t.Errorf("OpenString(%q) = %q, %v", legacy, got, err)
+		}
+	}
+}
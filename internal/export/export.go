@@ -0,0 +1,98 @@
+// Package export renders saved sessions in the formats users can take elsewhere.
+package export
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tuanta7/ekko/internal/session"
+)
+
+type Exporter interface {
+	// Ext is the file extension of the format, including the dot.
+	Ext() string
+	Export(w io.Writer, s *session.Session) error
+}
+
+// Options tunes the formats that support it.
+type Options struct {
+	// TimestampEvery is the minimum time between two timestamps of the plain text
+	// format, see ParseTimestamps.
+	TimestampEvery time.Duration
+}
+
+var exporters = map[string]func(opts Options) Exporter{
+	"json":     func(Options) Exporter { return JSON{} },
+	"markdown": func(Options) Exporter { return Markdown{} },
+	"text":     func(opts Options) Exporter { return Text{TimestampEvery: opts.TimestampEvery} },
+}
+
+func Get(format string, opts Options) (Exporter, error) {
+	newExporter, ok := exporters[strings.ToLower(format)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported export format %q, must be one of: %s", format, strings.Join(Formats(), ", "))
+	}
+	return newExporter(opts), nil
+}
+
+// ForPath returns the exporter of the format with the extension of path, JSON when no
+// format has it.
+func ForPath(path string, opts Options) Exporter {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, newExporter := range exporters {
+		if e := newExporter(opts); e.Ext() == ext {
+			return e
+		}
+	}
+	return JSON{}
+}
+
+// ParseTimestamps parses the timestamp granularity of the plain text format: chunk
+// stamps every chunk, none leaves them out, and a duration, e.g. 1m, stamps a chunk
+// when that long passed since the last timestamp. Chunks without timestamp continue
+// the paragraph.
+func ParseTimestamps(v string) (time.Duration, error) {
+	switch strings.ToLower(v) {
+	case "", "chunk":
+		return 0, nil
+	case "none":
+		return -1, nil
+	}
+
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid timestamp granularity %q, must be chunk, none or a duration, e.g. 1m", v)
+	}
+	return d, nil
+}
+
+// Formats returns the names of the supported formats.
+func Formats() []string {
+	formats := make([]string, 0, len(exporters))
+	for name := range exporters {
+		formats = append(formats, name)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// JSON writes the session in the persisted session format.
+type JSON struct{}
+
+func (JSON) Ext() string {
+	return ".json"
+}
+
+func (JSON) Export(w io.Writer, s *session.Session) error {
+	data, err := s.Marshal()
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
@@ -0,0 +1,96 @@
+package export
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tuanta7/ekko/internal/session"
+)
+
+// testSession returns a session with speakers, a failed chunk, a pause, bookmarks and
+// a summary.
+func testSession() *session.Session {
+	s := session.New()
+	s.Manifest.Title = "Budget"
+	s.Manifest.Tags = []string{"finance", "q4"}
+	s.Manifest.StartedAt = time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	s.Manifest.EndedAt = s.Manifest.StartedAt.Add(95 * time.Second)
+	s.Manifest.Backend, s.Manifest.Model = "whisper", "base"
+
+	for _, c := range []session.Chunk{
+		{Seq: 1, OffsetMS: 0, Text: "Let us plan the budget.", Speaker: "Alex"},
+		{Seq: 2, OffsetMS: 10_000, Text: "It is due on Friday.", Speaker: "Alex"},
+		{Seq: 3, OffsetMS: 20_000, Text: "I send it *today*.", Speaker: "Sam"},
+		{Seq: 4, OffsetMS: 30_000, Error: "timeout"},
+		{Seq: 5, OffsetMS: 70_000, Text: "Back from the break.", Speaker: "Sam", Paragraph: true},
+		{Seq: 6, OffsetMS: 80_000, Text: "  ", Speaker: "Sam"},
+		{Seq: 7, OffsetMS: 85_000, Text: "That is all.", Speaker: "Sam"},
+	} {
+		s.Append(c)
+	}
+	s.SetPause(session.Pause{StartMS: 40_000, EndMS: 65_000})
+	s.AddBookmark(session.Bookmark{OffsetMS: 25_000, Note: "action item"})
+	s.AddBookmark(session.Bookmark{OffsetMS: 90_000})
+	s.Summary = &session.Summary{
+		Text:        "The team planned the budget.",
+		Decisions:   []string{"Ship on Friday"},
+		ActionItems: []session.ActionItem{{Task: "Send the budget", Owner: "Sam"}, {Task: "Book a room"}},
+	}
+	return s
+}
+
+func export(t *testing.T, e Exporter, s *session.Session) string {
+	t.Helper()
+	var b strings.Builder
+	if err := e.Export(&b, s); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+func TestForPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"meeting.md", ".md"},
+		{"MEETING.MD", ".md"},
+		{"meeting.txt", ".txt"},
+		{"meeting.json", ".json"},
+		{"meeting.docx", ".json"},
+		{"meeting", ".json"},
+	}
+	for _, tt := range tests {
+		if got := ForPath(tt.path, Options{}).Ext(); got != tt.want {
+			t.Errorf("ForPath(%q) = %s, want %s", tt.path, got, tt.want)
+		}
+	}
+
+	if e := ForPath("meeting.txt", Options{TimestampEvery: time.Minute}); e.(Text).TimestampEvery != time.Minute {
+		t.Error("ForPath ignored the options")
+	}
+}
+
+func TestParseTimestamps(t *testing.T) {
+	tests := []struct {
+		v       string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"chunk", 0, false},
+		{"None", -1, false},
+		{"1m", time.Minute, false},
+		{"30s", 30 * time.Second, false},
+		{"0s", 0, true},
+		{"-1m", 0, true},
+		{"often", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseTimestamps(tt.v)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseTimestamps(%q) = %v, %v, want %v, error %v", tt.v, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
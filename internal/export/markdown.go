@@ -0,0 +1,109 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/tuanta7/ekko/internal/session"
+)
+
+// Markdown writes the session for wikis and tickets: a heading with the session details,
+// the summary, then the transcript with a timestamp and speaker label per chunk. The
+// bookmarks are callouts, as rendered by GitHub and Obsidian.
+type Markdown struct{}
+
+func (Markdown) Ext() string {
+	return ".md"
+}
+
+func (Markdown) Export(w io.Writer, s *session.Session) error {
+	bw := bufio.NewWriter(w)
+	m := s.Manifest
+
+	fmt.Fprintf(bw, "# %s\n", escapeMarkdown(title(m)))
+	var details []string
+	if !m.StartedAt.IsZero() {
+		details = append(details, "**Date:** "+m.StartedAt.Format("2006-01-02 15:04"))
+	}
+	if d := m.Duration(); d > 0 {
+		details = append(details, "**Duration:** "+d.Round(time.Second).String())
+	}
+	if len(m.Tags) > 0 {
+		details = append(details, "**Tags:** "+escapeMarkdown(strings.Join(m.Tags, ", ")))
+	}
+	if m.Backend != "" {
+		details = append(details, fmt.Sprintf("**Transcribed with:** %s (%s)", m.Backend, m.Model))
+	}
+	if len(details) > 0 {
+		bw.WriteString("\n- " + strings.Join(details, "\n- ") + "\n")
+	}
+
+	if sum := s.Summary; sum != nil {
+		fmt.Fprintf(bw, "\n## Summary\n\n%s\n", escapeMarkdown(sum.Text))
+		if len(sum.Decisions) > 0 {
+			bw.WriteString("\n### Decisions\n\n")
+			for _, d := range sum.Decisions {
+				fmt.Fprintf(bw, "- %s\n", escapeMarkdown(d))
+			}
+		}
+		if len(sum.ActionItems) > 0 {
+			bw.WriteString("\n### Action items\n\n")
+			for _, item := range sum.ActionItems {
+				fmt.Fprintf(bw, "- [ ] %s", escapeMarkdown(item.Task))
+				if item.Owner != "" {
+					fmt.Fprintf(bw, " (**%s**)", escapeMarkdown(item.Owner))
+				}
+				bw.WriteString("\n")
+			}
+		}
+	}
+
+	bw.WriteString("\n## Transcript\n")
+	for _, item := range s.Timeline() {
+		stamp := session.FormatOffset(item.Offset())
+		switch {
+		case item.Pause != nil:
+			fmt.Fprintf(bw, "\n*[%s] Paused for %s*\n", stamp, item.Pause.Duration().Round(time.Second))
+		case item.Bookmark != nil:
+			fmt.Fprintf(bw, "\n> [!NOTE]\n> **Bookmark at %s**", stamp)
+			if note := item.Bookmark.Note; note != "" {
+				fmt.Fprintf(bw, " %s", escapeMarkdown(note))
+			}
+			bw.WriteString("\n")
+		case item.Chunk.Error != "":
+			fmt.Fprintf(bw, "\n*[%s] Transcription failed: %s*\n", stamp, escapeMarkdown(item.Chunk.Error))
+		default:
+			text := strings.TrimSpace(item.Chunk.Text)
+			if text == "" {
+				continue
+			}
+			label := "[" + stamp + "]"
+			if item.Chunk.Speaker != "" {
+				label += " " + escapeMarkdown(item.Chunk.Speaker) + ":"
+			}
+			fmt.Fprintf(bw, "\n**%s** %s\n", label, escapeMarkdown(text))
+		}
+	}
+
+	return bw.Flush()
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `[`, `\[`, `]`, `\]`, `<`, `&lt;`, `>`, `&gt;`, "\n", " ",
+)
+
+// escapeMarkdown keeps transcribed text from being read as Markdown, e.g. a spoken
+// asterisk turning the rest of the line into italics.
+func escapeMarkdown(text string) string {
+	return markdownEscaper.Replace(text)
+}
+
+func title(m session.Manifest) string {
+	if m.Title == "" {
+		return "Untitled session"
+	}
+	return m.Title
+}
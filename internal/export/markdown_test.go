@@ -0,0 +1,125 @@
+package export
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tuanta7/ekko/internal/session"
+)
+
+func TestMarkdown(t *testing.T) {
+	const want = `# Budget
+
+- **Date:** 2026-10-19 10:00
+- **Duration:** 1m35s
+- **Tags:** finance, q4
+- **Transcribed with:** whisper (base)
+
+## Summary
+
+The team planned the budget.
+
+### Decisions
+
+- Ship on Friday
+
+### Action items
+
+- [ ] Send the budget (**Sam**)
+- [ ] Book a room
+
+## Transcript
+
+**[00:00:00] Alex:** Let us plan the budget.
+
+**[00:00:10] Alex:** It is due on Friday.
+
+**[00:00:20] Sam:** I send it \*today\*.
+
+> [!NOTE]
+> **Bookmark at 00:00:25** action item
+
+*[00:00:30] Transcription failed: timeout*
+
+*[00:00:40] Paused for 25s*
+
+**[00:01:10] Sam:** Back from the break.
+
+**[00:01:25] Sam:** That is all.
+
+> [!NOTE]
+> **Bookmark at 00:01:30**
+`
+	if got := export(t, Markdown{}, testSession()); got != want {
+		t.Errorf("Markdown:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestMarkdownSections(t *testing.T) {
+	tests := []struct {
+		name    string
+		edit    func(s *session.Session)
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "no summary",
+			edit:    func(s *session.Session) { s.Summary = nil },
+			notWant: []string{"## Summary", "### Decisions", "### Action items"},
+		},
+		{
+			name: "summary without items",
+			edit: func(s *session.Session) {
+				s.Summary.Decisions, s.Summary.ActionItems = nil, nil
+			},
+			want:    []string{"## Summary\n\nThe team planned the budget.\n\n## Transcript"},
+			notWant: []string{"### Decisions", "### Action items"},
+		},
+		{
+			name: "untitled without details",
+			edit: func(s *session.Session) {
+				s.Manifest = session.Manifest{}
+			},
+			want:    []string{"# Untitled session\n\n## Summary"},
+			notWant: []string{"**Date:**", "**Duration:**", "**Tags:**", "**Transcribed with:**"},
+		},
+		{
+			name: "escaped text",
+			edit: func(s *session.Session) {
+				s.Manifest.Title = "Q4 <budget>"
+				s.Bookmarks[0].Note = "see [doc](x)\nnow"
+				s.Chunks[0].Speaker = "_Alex_"
+			},
+			want: []string{
+				"# Q4 &lt;budget&gt;",
+				"**Bookmark at 00:00:25** see \\[doc\\](x) now",
+				"**[00:00:00] \\_Alex\\_:**",
+			},
+		},
+		{
+			name: "no speaker",
+			edit: func(s *session.Session) {
+				s.Chunks[0].Speaker = ""
+			},
+			want: []string{"**[00:00:00]** Let us plan the budget."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := testSession()
+			tt.edit(s)
+			got := export(t, Markdown{}, s)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("output lacks %q:\n%s", want, got)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("output has %q:\n%s", notWant, got)
+				}
+			}
+		})
+	}
+}
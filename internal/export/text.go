@@ -0,0 +1,150 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/tuanta7/ekko/internal/session"
+)
+
+// Text writes the session as plain text, a header and the summary followed by the
+// transcript. A paragraph starts at each timestamp, speaker change and paragraph of the
+// formatted transcript, how often the timestamps are written is set with TimestampEvery.
+type Text struct {
+	// TimestampEvery is the minimum time between two timestamps, zero stamps every chunk
+	// and a negative value none.
+	TimestampEvery time.Duration
+}
+
+func (Text) Ext() string {
+	return ".txt"
+}
+
+func (t Text) Export(w io.Writer, s *session.Session) error {
+	bw := bufio.NewWriter(w)
+	m := s.Manifest
+
+	bw.WriteString(title(m) + "\n")
+	var details []string
+	if !m.StartedAt.IsZero() {
+		details = append(details, m.StartedAt.Format("2006-01-02 15:04"))
+	}
+	if d := m.Duration(); d > 0 {
+		details = append(details, d.Round(time.Second).String())
+	}
+	if len(m.Tags) > 0 {
+		details = append(details, strings.Join(m.Tags, ", "))
+	}
+	if len(details) > 0 {
+		bw.WriteString(strings.Join(details, ", ") + "\n")
+	}
+
+	if sum := s.Summary; sum != nil {
+		fmt.Fprintf(bw, "\nSummary\n%s\n", sum.Text)
+		if len(sum.Decisions) > 0 {
+			bw.WriteString("\nDecisions\n")
+			for _, d := range sum.Decisions {
+				fmt.Fprintf(bw, "- %s\n", d)
+			}
+		}
+		if len(sum.ActionItems) > 0 {
+			bw.WriteString("\nAction items\n")
+			for _, item := range sum.ActionItems {
+				if item.Owner == "" {
+					fmt.Fprintf(bw, "- %s\n", item.Task)
+				} else {
+					fmt.Fprintf(bw, "- %s (%s)\n", item.Task, item.Owner)
+				}
+			}
+		}
+	}
+
+	bw.WriteString("\n")
+	p := paragraphs{w: bw, every: t.TimestampEvery}
+	for _, item := range s.Timeline() {
+		switch {
+		case item.Pause != nil:
+			p.line(item.Offset(), fmt.Sprintf("(paused for %s)", item.Pause.Duration().Round(time.Second)))
+		case item.Bookmark != nil:
+			note := "(bookmark)"
+			if item.Bookmark.Note != "" {
+				note = "(bookmark: " + item.Bookmark.Note + ")"
+			}
+			p.line(item.Offset(), note)
+		case item.Chunk.Error != "":
+			p.line(item.Offset(), "(transcription failed: "+item.Chunk.Error+")")
+		default:
+			p.chunk(item.Chunk)
+		}
+	}
+	p.end()
+
+	return bw.Flush()
+}
+
+// paragraphs joins the chunks of the plain text transcript into paragraphs.
+type paragraphs struct {
+	w       *bufio.Writer
+	every   time.Duration
+	open    bool // a paragraph is being written
+	speaker string
+	stamped bool          // a timestamp was written
+	last    time.Duration // offset of the last timestamp
+}
+
+func (p *paragraphs) chunk(c *session.Chunk) {
+	text := strings.TrimSpace(c.Text)
+	if text == "" {
+		return
+	}
+
+	stamp := p.due(c.Offset())
+	if p.open && !stamp && !c.Paragraph && c.Speaker == p.speaker {
+		p.w.WriteString(" " + text)
+		return
+	}
+
+	p.end()
+	p.prefix(c.Offset(), stamp)
+	if c.Speaker != "" {
+		p.w.WriteString(c.Speaker + ": ")
+	}
+	p.w.WriteString(text)
+	p.open, p.speaker = true, c.Speaker
+}
+
+// line writes a line of its own, e.g. a bookmark, stamped unless timestamps are disabled.
+func (p *paragraphs) line(offset time.Duration, text string) {
+	p.end()
+	p.prefix(offset, p.every >= 0)
+	p.w.WriteString(text + "\n")
+}
+
+func (p *paragraphs) due(offset time.Duration) bool {
+	switch {
+	case p.every < 0:
+		return false
+	case p.every == 0 || !p.stamped:
+		return true
+	default:
+		return offset-p.last >= p.every
+	}
+}
+
+func (p *paragraphs) prefix(offset time.Duration, stamp bool) {
+	if !stamp {
+		return
+	}
+	p.w.WriteString("[" + session.FormatOffset(offset) + "] ")
+	p.stamped, p.last = true, offset
+}
+
+func (p *paragraphs) end() {
+	if p.open {
+		p.w.WriteString("\n")
+		p.open = false
+	}
+}
@@ -0,0 +1,115 @@
+package export
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tuanta7/ekko/internal/session"
+)
+
+const textHeader = `Budget
+2026-10-19 10:00, 1m35s, finance, q4
+
+Summary
+The team planned the budget.
+
+Decisions
+- Ship on Friday
+
+Action items
+- Send the budget (Sam)
+- Book a room
+
+`
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		name  string
+		every time.Duration
+		want  string
+	}{
+		{
+			name:  "every chunk",
+			every: 0,
+			want: `[00:00:00] Alex: Let us plan the budget.
+[00:00:10] Alex: It is due on Friday.
+[00:00:20] Sam: I send it *today*.
+[00:00:25] (bookmark: action item)
+[00:00:30] (transcription failed: timeout)
+[00:00:40] (paused for 25s)
+[00:01:10] Sam: Back from the break.
+[00:01:25] Sam: That is all.
+[00:01:30] (bookmark)
+`,
+		},
+		{
+			name:  "every 30 seconds",
+			every: 30 * time.Second,
+			want: `[00:00:00] Alex: Let us plan the budget. It is due on Friday.
+Sam: I send it *today*.
+[00:00:25] (bookmark: action item)
+[00:00:30] (transcription failed: timeout)
+[00:00:40] (paused for 25s)
+[00:01:10] Sam: Back from the break. That is all.
+[00:01:30] (bookmark)
+`,
+		},
+		{
+			name:  "none",
+			every: -1,
+			want: `Alex: Let us plan the budget. It is due on Friday.
+Sam: I send it *today*.
+(bookmark: action item)
+(transcription failed: timeout)
+(paused for 25s)
+Sam: Back from the break. That is all.
+(bookmark)
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := export(t, Text{TimestampEvery: tt.every}, testSession()); got != textHeader+tt.want {
+				t.Errorf("Text:\n%s\nwant:\n%s", got, textHeader+tt.want)
+			}
+		})
+	}
+}
+
+func TestTextParagraphs(t *testing.T) {
+	s := session.New()
+	s.Manifest.Title = "Standup"
+	for _, c := range []session.Chunk{
+		{Seq: 1, OffsetMS: 0, Text: "First point."},
+		{Seq: 2, OffsetMS: 10_000, Text: "Still the first."},
+		{Seq: 3, OffsetMS: 20_000, Text: "Second point.", Paragraph: true},
+		{Seq: 4, OffsetMS: 30_000, Text: "Third point.", Speaker: "Sam"},
+		{Seq: 5, OffsetMS: 70_000, Text: "Later on."},
+	} {
+		s.Append(c)
+	}
+
+	tests := []struct {
+		name  string
+		every time.Duration
+		want  string
+	}{
+		{"none", -1, "First point. Still the first.\nSecond point.\nSam: Third point.\nLater on.\n"},
+		{"every minute", time.Minute, "[00:00:00] First point. Still the first.\nSecond point.\nSam: Third point.\n[00:01:10] Later on.\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := "Standup\n\n" + tt.want
+			if got := export(t, Text{TimestampEvery: tt.every}, s); got != want {
+				t.Errorf("Text:\n%q\nwant:\n%q", got, want)
+			}
+		})
+	}
+
+	// the summary is left out when there is none
+	if got := export(t, Text{}, s); strings.Contains(got, "Summary") {
+		t.Errorf("Text without summary:\n%s", got)
+	}
+}
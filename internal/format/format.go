@@ -0,0 +1,166 @@
+// Package format restores the punctuation and capitalization of transcribed chunks. The
+// transcribers are asked for the plain spoken words, which run on with no sentence
+// breaks.
+package format
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type Mode string
+
+const (
+	// RulesMode fixes the spacing and the capitalization, it runs offline and adds no delay.
+	RulesMode Mode = "rules"
+	// LLMMode asks the summary language model to punctuate each chunk.
+	LLMMode Mode = "llm"
+)
+
+// Formatter formats the text of a transcribed chunk. prev is the formatted text of the
+// chunk before it in the same paragraph, empty when the chunk starts a paragraph.
+type Formatter interface {
+	Format(ctx context.Context, prev, text string) (string, error)
+}
+
+// Rules is the rule-based formatter. It drops the caption tags of Whisper, e.g.
+// [BLANK_AUDIO], fixes the spacing around punctuation and capitalizes the sentences.
+type Rules struct {
+	// Language enables the rules of a language, e.g. "en" capitalizes "i".
+	Language string
+}
+
+func NewRules(language string) Rules {
+	return Rules{Language: language}
+}
+
+var (
+	// captionRE matches the non-speech annotations, e.g. [BLANK_AUDIO], [MUSIC PLAYING] or
+	// (applause), and not the masks of the redaction, e.g. [EMAIL]
+	captionRE = regexp.MustCompile(`\[\s*(?i:blank_audio|no speech|applause|laughter|music|silence|inaudible|noise|sound)[^\]]*]` +
+		`|\(\s*(?i:applause|laughter|laughs|music|silence|inaudible|noise)\s*\)`)
+	spaceRE       = regexp.MustCompile(`\s+`)
+	spaceBeforeRE = regexp.MustCompile(`\s+([,.!?;:…])`)
+	spaceAfterRE  = regexp.MustCompile(`([,!?;…])(\pL)`)
+	englishIRE    = regexp.MustCompile(`\bi('m|'ll|'ve|'d)?\b`)
+)
+
+func (r Rules) Format(_ context.Context, prev, text string) (string, error) {
+	text = clean(text)
+	if text == "" {
+		return "", nil
+	}
+
+	text = spaceBeforeRE.ReplaceAllString(text, "$1")
+	text = spaceAfterRE.ReplaceAllString(text, "$1 $2")
+	if r.Language == "en" {
+		text = englishIRE.ReplaceAllStringFunc(text, func(s string) string {
+			return "I" + s[1:]
+		})
+	}
+
+	return capitalize(prev, text), nil
+}
+
+// clean drops the caption tags and the extra whitespace.
+func clean(text string) string {
+	text = captionRE.ReplaceAllString(text, " ")
+	return strings.TrimSpace(spaceRE.ReplaceAllString(text, " "))
+}
+
+// capitalize upper-cases the first letter of each sentence, the text starts one when
+// prev is empty or ends with a sentence.
+func capitalize(prev, text string) string {
+	upper := endsSentence(prev)
+	var b strings.Builder
+	b.Grow(len(text))
+	for _, r := range text {
+		if upper && unicode.IsLetter(r) {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+		switch {
+		case strings.ContainsRune(".!?…", r):
+			upper = true
+		case !unicode.IsSpace(r) && !strings.ContainsRune(`"'”’)`, r):
+			upper = upper && !unicode.IsDigit(r)
+		}
+	}
+	return b.String()
+}
+
+func endsSentence(text string) bool {
+	text = strings.TrimRight(strings.TrimSpace(text), `"'”’)`)
+	if text == "" {
+		return true
+	}
+	r, _ := utf8.DecodeLastRuneInString(text)
+	return strings.ContainsRune(".!?…", r)
+}
+
+// Punctuator is a language model punctuating a chunk, see the summary package.
+type Punctuator interface {
+	Punctuate(ctx context.Context, prev, text string) (string, error)
+}
+
+// LLM formats the chunks with a language model. The reply is checked against the
+// transcribed words, a model answering the speech instead of punctuating it is an error.
+type LLM struct {
+	model Punctuator
+	rules Rules
+}
+
+func NewLLM(model Punctuator, language string) *LLM {
+	return &LLM{model: model, rules: NewRules(language)}
+}
+
+func (l *LLM) Format(ctx context.Context, prev, text string) (string, error) {
+	text = clean(text)
+	if text == "" {
+		return "", nil
+	}
+
+	formatted, err := l.model.Punctuate(ctx, prev, text)
+	if err != nil {
+		return "", err
+	}
+
+	formatted = strings.TrimSpace(formatted)
+	if !sameWords(text, formatted) {
+		return "", fmt.Errorf("the model changed the words of the chunk: %q", formatted)
+	}
+
+	// the model does not always follow the spacing rules
+	return l.rules.Format(ctx, prev, formatted)
+}
+
+// sameWords reports whether the words of b are, case and punctuation aside, about
+// those of a. A few words may differ, e.g. a number written in digits.
+func sameWords(a, b string) bool {
+	wa, wb := words(a), words(b)
+	common := 0
+	seen := make(map[string]int, len(wa))
+	for _, w := range wa {
+		seen[w]++
+	}
+	for _, w := range wb {
+		if seen[w] > 0 {
+			seen[w]--
+			common++
+		}
+	}
+
+	tolerance := 2 + len(wa)/10
+	return len(wa)-common <= tolerance && len(wb)-common <= tolerance
+}
+
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	})
+}
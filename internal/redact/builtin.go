@@ -0,0 +1,149 @@
+package redact
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var builtins = map[string]func() Detector{
+	"email": func() Detector {
+		return &Regex{kind: "EMAIL", re: regexp.MustCompile(`[\pL\pN._%+-]+@[\pL\pN-]+(?:\.[\pL\pN-]+)+`)}
+	},
+	"phone": func() Detector {
+		return &Regex{
+			kind: "PHONE",
+			re:   regexp.MustCompile(`(?:\+|\(|\b)\d(?:[ .\-()]{0,2}\d){6,14}\b`),
+			valid: func(s string) bool {
+				return !dateRE.MatchString(s) && digitsBetween(s, 7, 15)
+			},
+		}
+	},
+	"card": func() Detector {
+		return &Regex{kind: "CARD", re: regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`), valid: luhn}
+	},
+}
+
+// Builtin returns the detector with the given name, see Builtins.
+func Builtin(name string) (Detector, error) {
+	d, ok := builtins[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown redaction detector %q, must be one of: %s", name, strings.Join(Builtins(), ", "))
+	}
+	return d(), nil
+}
+
+// Builtins returns the names of the built-in detectors.
+func Builtins() []string {
+	names := make([]string, 0, len(builtins))
+	for name := range builtins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// dateRE matches the dates the phone pattern would take for a number, e.g. 2025-01-31.
+var dateRE = regexp.MustCompile(`^\d{4}[-./]\d{2}[-./]\d{2}$|^\d{2}[-./]\d{2}[-./]\d{4}$`)
+
+func digitsBetween(s string, low, high int) bool {
+	n := len(digits(s))
+	return n >= low && n <= high
+}
+
+// luhn reports whether the digits of s have a valid Luhn checksum, as card numbers do.
+func luhn(s string) bool {
+	d := digits(s)
+	sum := 0
+	for i := range d {
+		n := int(d[len(d)-1-i] - '0')
+		if i%2 == 1 {
+			if n *= 2; n > 9 {
+				n -= 9
+			}
+		}
+		sum += n
+	}
+	return sum%10 == 0
+}
+
+func digits(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+// Dictionary finds the terms of a list, e.g. the names of the employees, as whole words
+// regardless of case.
+type Dictionary struct {
+	kind string
+	re   *regexp.Regexp
+}
+
+func NewDictionary(kind string, terms []string) *Dictionary {
+	var quoted []string
+	for _, t := range terms {
+		if t = strings.TrimSpace(t); t != "" {
+			quoted = append(quoted, regexp.QuoteMeta(t))
+		}
+	}
+	d := &Dictionary{kind: strings.ToUpper(kind)}
+	if len(quoted) == 0 {
+		return d
+	}
+
+	// the longest term wins, e.g. a full name over the first name
+	sort.Slice(quoted, func(i, j int) bool { return len(quoted[i]) > len(quoted[j]) })
+	d.re = regexp.MustCompile(`(?i)` + strings.Join(quoted, "|"))
+	return d
+}
+
+func (d *Dictionary) Kind() string {
+	return d.kind
+}
+
+func (d *Dictionary) Find(text string) []Match {
+	if d.re == nil {
+		return nil
+	}
+
+	var matches []Match
+	for _, loc := range d.re.FindAllStringIndex(text, -1) {
+		// \b only knows ASCII words, which would miss the names with accents
+		before, _ := utf8.DecodeLastRuneInString(text[:loc[0]])
+		after, _ := utf8.DecodeRuneInString(text[loc[1]:])
+		if isWord(before) || isWord(after) {
+			continue
+		}
+		matches = append(matches, Match{Start: loc[0], End: loc[1]})
+	}
+	return matches
+}
+
+func isWord(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// LoadDictionary reads the terms of a dictionary from a file, one per line. Empty lines
+// and lines starting with # are skipped.
+func LoadDictionary(kind, path string) (*Dictionary, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("redaction dictionary %s: %w", kind, err)
+	}
+
+	var terms []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			terms = append(terms, line)
+		}
+	}
+	return NewDictionary(kind, terms), nil
+}
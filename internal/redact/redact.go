@@ -0,0 +1,183 @@
+// Package redact removes personal data from transcripts, e.g. the email addresses and
+// phone numbers, found by pluggable detectors.
+package redact
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+type Mode string
+
+const (
+	// MaskMode replaces the data with its kind, e.g. [EMAIL].
+	MaskMode Mode = "mask"
+	// HashMode replaces the data with its kind and a keyed hash, e.g. [EMAIL:5f1c2a9e],
+	// so the same value can be followed through a transcript without being stored.
+	HashMode Mode = "hash"
+	// DropMode removes the data.
+	DropMode Mode = "drop"
+)
+
+// Match is the byte range of personal data in a text.
+type Match struct {
+	Start, End int
+}
+
+// Detector finds one kind of personal data.
+type Detector interface {
+	// Kind names the data in the masks, e.g. EMAIL.
+	Kind() string
+	Find(text string) []Match
+}
+
+// Redactor replaces what its detectors find according to its mode.
+type Redactor struct {
+	mode      Mode
+	key       []byte
+	detectors []Detector
+}
+
+// New creates a redactor, the hash mode needs a key. When two detectors find the same
+// text, the first one names it.
+func New(mode Mode, key []byte, detectors ...Detector) (*Redactor, error) {
+	switch mode {
+	case MaskMode, DropMode:
+	case HashMode:
+		if len(key) == 0 {
+			return nil, errors.New("the hash redaction mode needs a key")
+		}
+	default:
+		return nil, fmt.Errorf("invalid redaction mode %q, must be one of: mask, hash, drop", mode)
+	}
+
+	return &Redactor{mode: mode, key: key, detectors: detectors}, nil
+}
+
+type found struct {
+	Match
+	detector int
+}
+
+// maskRE matches the masks of a redacted text, e.g. [EMAIL] or [PHONE:5f1c2a9e].
+var maskRE = regexp.MustCompile(`\[[\p{Lu}\p{N}_-]+(?::[0-9a-f]{8})?\]`)
+
+// Redact replaces the personal data found in text. The masks of a text already redacted
+// are left as they are, so redacting it again changes nothing.
+func (r *Redactor) Redact(text string) string {
+	masks := maskRE.FindAllStringIndex(text, -1)
+	inMask := func(m Match) bool {
+		for _, loc := range masks {
+			if m.Start < loc[1] && m.End > loc[0] {
+				return true
+			}
+		}
+		return false
+	}
+
+	var all []found
+	for i, d := range r.detectors {
+		for _, m := range d.Find(text) {
+			if !inMask(m) {
+				all = append(all, found{Match: m, detector: i})
+			}
+		}
+	}
+	if len(all) == 0 {
+		return text
+	}
+
+	// the longest match wins among overlapping ones
+	sort.Slice(all, func(i, j int) bool {
+		a, b := all[i], all[j]
+		switch {
+		case a.Start != b.Start:
+			return a.Start < b.Start
+		case a.End != b.End:
+			return a.End > b.End
+		default:
+			return a.detector < b.detector
+		}
+	})
+
+	var b strings.Builder
+	last := 0
+	for _, f := range all {
+		if f.Start < last {
+			continue
+		}
+		b.WriteString(text[last:f.Start])
+		b.WriteString(r.replacement(r.detectors[f.detector].Kind(), text[f.Start:f.End]))
+		last = f.End
+	}
+	b.WriteString(text[last:])
+
+	if r.mode == DropMode {
+		return strings.Join(strings.Fields(b.String()), " ")
+	}
+	return b.String()
+}
+
+func (r *Redactor) replacement(kind, value string) string {
+	switch r.mode {
+	case HashMode:
+		mac := hmac.New(sha256.New, r.key)
+		mac.Write([]byte(normalize(value)))
+		return "[" + kind + ":" + hex.EncodeToString(mac.Sum(nil))[:8] + "]"
+	case DropMode:
+		return ""
+	default:
+		return "[" + kind + "]"
+	}
+}
+
+// normalize makes the differently written forms of a value hash the same, e.g. the
+// digits only of a phone number.
+func normalize(value string) string {
+	if strings.IndexFunc(value, unicode.IsLetter) < 0 {
+		return strings.Map(func(r rune) rune {
+			if unicode.IsDigit(r) {
+				return r
+			}
+			return -1
+		}, value)
+	}
+	return strings.ToLower(value)
+}
+
+// Regex finds the matches of a regular expression.
+type Regex struct {
+	kind string
+	re   *regexp.Regexp
+	// valid filters the matches, e.g. the card numbers with a valid checksum
+	valid func(string) bool
+}
+
+func NewRegex(kind, pattern string) (*Regex, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("redaction rule %s: %w", kind, err)
+	}
+	return &Regex{kind: strings.ToUpper(kind), re: re}, nil
+}
+
+func (d *Regex) Kind() string {
+	return d.kind
+}
+
+func (d *Regex) Find(text string) []Match {
+	var matches []Match
+	for _, loc := range d.re.FindAllStringIndex(text, -1) {
+		if d.valid == nil || d.valid(text[loc[0]:loc[1]]) {
+			matches = append(matches, Match{Start: loc[0], End: loc[1]})
+		}
+	}
+	return matches
+}
@@ -0,0 +1,322 @@
+package redact
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func builtin(t *testing.T, name string) Detector {
+	t.Helper()
+	d, err := Builtin(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func masker(t *testing.T, detectors ...Detector) *Redactor {
+	t.Helper()
+	r, err := New(MaskMode, nil, detectors...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestEmail(t *testing.T) {
+	r := masker(t, builtin(t, "email"))
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"write to sam@example.com today", "write to [EMAIL] today"},
+		{"sam.lee+work@mail.example.co.uk.", "[EMAIL]."},
+		{"josé@exemple.fr", "[EMAIL]"},
+		{"meet @ noon", "meet @ noon"},
+		{"sam@localhost", "sam@localhost"},
+	}
+	for _, tt := range tests {
+		if got := r.Redact(tt.text); got != tt.want {
+			t.Errorf("Redact(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestPhone(t *testing.T) {
+	r := masker(t, builtin(t, "phone"))
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"call 555-123-4567 now", "call [PHONE] now"},
+		{"call +1 (555) 123-4567", "call [PHONE]"},
+		{"call 0912 345 678", "call [PHONE]"},
+		{"call 5551234", "call [PHONE]"},
+		// dates are not phone numbers
+		{"due on 2025-01-31", "due on 2025-01-31"},
+		{"due on 31.01.2025", "due on 31.01.2025"},
+		{"due on 01/31/2025", "due on 01/31/2025"},
+		// too short or too long
+		{"room 123456", "room 123456"},
+		{"id 1234567890123456", "id 1234567890123456"},
+		{"the budget is 2 million", "the budget is 2 million"},
+	}
+	for _, tt := range tests {
+		if got := r.Redact(tt.text); got != tt.want {
+			t.Errorf("Redact(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestCard(t *testing.T) {
+	r := masker(t, builtin(t, "card"))
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"card 4111 1111 1111 1111 expires", "card [CARD] expires"},
+		{"card 4111-1111-1111-1111", "card [CARD]"},
+		{"card 378282246310005", "card [CARD]"},
+		// an invalid checksum
+		{"order 4111 1111 1111 1112", "order 4111 1111 1111 1112"},
+		{"order 1234567890123", "order 1234567890123"},
+		// too short
+		{"order 411111111111", "order 411111111111"},
+	}
+	for _, tt := range tests {
+		if got := r.Redact(tt.text); got != tt.want {
+			t.Errorf("Redact(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestLuhn(t *testing.T) {
+	tests := []struct {
+		s    string
+		want bool
+	}{
+		{"4111111111111111", true},
+		{"4111 1111 1111 1111", true},
+		{"5500-0000-0000-0004", true},
+		{"79927398713", true},
+		{"79927398710", false},
+		{"4111111111111112", false},
+	}
+	for _, tt := range tests {
+		if got := luhn(tt.s); got != tt.want {
+			t.Errorf("luhn(%q) = %v, want %v", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestDictionary(t *testing.T) {
+	r := masker(t, NewDictionary("name", []string{"Sam", "Sam Lee", " ", "José", "A.B."}))
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"ask Sam about it", "ask [NAME] about it"},
+		{"ask sam, then SAM", "ask [NAME], then [NAME]"},
+		// the longest term wins
+		{"ask Sam Lee about it", "ask [NAME] about it"},
+		// whole words only
+		{"the sample and Samuel", "the sample and Samuel"},
+		{"Sam2 and 2Sam", "Sam2 and 2Sam"},
+		{"José and Joséphine", "[NAME] and Joséphine"},
+		{"Samé", "Samé"},
+		// the terms are not patterns
+		{"ask A.B. or AxBx", "ask [NAME] or AxBx"},
+	}
+	for _, tt := range tests {
+		if got := r.Redact(tt.text); got != tt.want {
+			t.Errorf("Redact(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+
+	if got := masker(t, NewDictionary("name", nil)).Redact("ask Sam"); got != "ask Sam" {
+		t.Errorf("empty dictionary: Redact = %q", got)
+	}
+}
+
+func TestLoadDictionary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "names.txt")
+	if err := os.WriteFile(path, []byte("# employees\nSam\n\n  Alex Kim  \n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := LoadDictionary("name", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := masker(t, d).Redact("Sam and Alex Kim, not employees"); got != "[NAME] and [NAME], not employees" {
+		t.Errorf("Redact = %q", got)
+	}
+
+	if _, err = LoadDictionary("name", filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("loaded a missing dictionary")
+	}
+}
+
+func TestRegex(t *testing.T) {
+	d, err := NewRegex("employee_id", `\bE-\d{5}\b`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := masker(t, d).Redact("badge E-12345, not E-123"); got != "badge [EMPLOYEE_ID], not E-123" {
+		t.Errorf("Redact = %q", got)
+	}
+
+	if _, err = NewRegex("bad", `(`); err == nil {
+		t.Error("compiled an invalid pattern")
+	}
+}
+
+func TestOverlap(t *testing.T) {
+	employeeID, err := NewRegex("id", `\b\d{4}\b`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		detectors []Detector
+		text      string
+		want      string
+	}{
+		{
+			"the longest match wins",
+			[]Detector{employeeID, builtin(t, "phone")},
+			"call 5551 2345",
+			"call [PHONE]",
+		},
+		{
+			"a card is not also a phone",
+			[]Detector{builtin(t, "phone"), builtin(t, "card")},
+			"card 4111 1111 1111 1111",
+			"card [CARD]",
+		},
+		{
+			"the first detector names the same text",
+			[]Detector{NewDictionary("name", []string{"sam@example.com"}), builtin(t, "email")},
+			"write to sam@example.com",
+			"write to [NAME]",
+		},
+		{
+			"a name inside an email",
+			[]Detector{NewDictionary("name", []string{"Sam"}), builtin(t, "email")},
+			"Sam wrote from sam@example.com",
+			"[NAME] wrote from [EMAIL]",
+		},
+		{
+			"adjacent matches",
+			[]Detector{NewDictionary("name", []string{"Sam", "Lee"})},
+			"Sam Lee",
+			"[NAME] [NAME]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := masker(t, tt.detectors...).Redact(tt.text); got != tt.want {
+				t.Errorf("Redact(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModes(t *testing.T) {
+	detectors := []Detector{builtin(t, "email"), builtin(t, "phone")}
+	const text = "Sam (sam@example.com, 555-123-4567) and SAM@example.com"
+
+	mask, err := New(MaskMode, nil, detectors...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := mask.Redact(text); got != "Sam ([EMAIL], [PHONE]) and [EMAIL]" {
+		t.Errorf("mask: %q", got)
+	}
+
+	drop, err := New(DropMode, nil, detectors...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := drop.Redact("write to sam@example.com  or call 555-123-4567 today"); got != "write to or call today" {
+		t.Errorf("drop: %q", got)
+	}
+
+	hash, err := New(HashMode, []byte("key"), detectors...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := hash.Redact(text)
+	hashes := regexp.MustCompile(`\[(EMAIL|PHONE):[0-9a-f]{8}\]`).FindAllString(got, -1)
+	if len(hashes) != 3 {
+		t.Fatalf("hash: %q, want 3 hashes", got)
+	}
+	if hashes[0] != hashes[2] {
+		t.Errorf("hash: %q, want the same hash for an email written differently", got)
+	}
+	if strings.Contains(got, "example") || strings.Contains(got, "4567") {
+		t.Errorf("hash: %q, the data is left", got)
+	}
+
+	// the hashes depend on the key
+	other, err := New(HashMode, []byte("other"), detectors...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.Redact(text) == got {
+		t.Error("hash: the same hashes with another key")
+	}
+
+	// the same phone number written differently
+	if a, b := hash.Redact("555-123-4567"), hash.Redact("555 123 4567"); a != b {
+		t.Errorf("hash: %q and %q, want the same", a, b)
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(HashMode, nil); err == nil {
+		t.Error("created a hash redactor without a key")
+	}
+	if _, err := New("blur", nil); err == nil {
+		t.Error("created a redactor with an invalid mode")
+	}
+	if _, err := Builtin("ssn"); err == nil {
+		t.Error("found an unknown built-in detector")
+	}
+}
+
+func TestRedactTwice(t *testing.T) {
+	name := NewDictionary("name", []string{"Sam", "email", "phone"})
+	detectors := []Detector{name, builtin(t, "email"), builtin(t, "phone"), builtin(t, "card")}
+	const text = "Sam: email sam@example.com or phone 555-123-4567, card 4111 1111 1111 1111"
+
+	for _, mode := range []Mode{MaskMode, HashMode, DropMode} {
+		t.Run(string(mode), func(t *testing.T) {
+			r, err := New(mode, []byte("key"), detectors...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			once := r.Redact(text)
+			if twice := r.Redact(once); twice != once {
+				t.Errorf("redacted again: %q, want %q", twice, once)
+			}
+		})
+	}
+
+	// masks whose hash looks like a phone number are left as they are
+	r := masker(t, detectors...)
+	for _, redacted := range []string{"[PHONE:55512345]", "call [PHONE:12345678] or [CARD:41111111]", "[NAME] and [EMAIL]"} {
+		if got := r.Redact(redacted); got != redacted {
+			t.Errorf("Redact(%q) = %q", redacted, got)
+		}
+	}
+}
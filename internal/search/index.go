@@ -0,0 +1,343 @@
+// Package search is a full-text index over saved sessions, kept as an inverted index
+// in a single file.
+package search
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/tuanta7/ekko/internal/encrypt"
+	"github.com/tuanta7/ekko/internal/session"
+)
+
+// ErrEmptyQuery is returned for queries without any word.
+var ErrEmptyQuery = errors.New("empty search query")
+
+// indexVersion is bumped when the file layout or the tokenizer changes, older indexes
+// are rebuilt.
+const indexVersion = 2
+
+// Result is a chunk matching a query.
+type Result struct {
+	ID        string
+	Title     string
+	StartedAt time.Time
+	Seq       int
+	Offset    time.Duration
+	Text      string
+	// Terms are the indexed words that matched, for highlighting.
+	Terms []string
+	Score float64
+}
+
+type document struct {
+	ID        string
+	IndexedAt time.Time
+	Title     string
+	StartedAt time.Time
+	Chunks    []chunk
+}
+
+type chunk struct {
+	Seq      int
+	OffsetMS int64
+	Text     string
+}
+
+type posting struct {
+	Doc   string // session ID
+	Chunk int    // position in the document chunks
+	Count int
+}
+
+// Index maps the words of the saved sessions to the chunks they appear in. It is
+// not safe for concurrent use.
+type Index struct {
+	Version  int
+	Docs     map[string]*document
+	Postings map[string][]posting
+
+	path  string
+	key   *encrypt.Key
+	terms []string // sorted keys of Postings, for prefix matching
+}
+
+// Open loads the index stored at path, sealed with key when not nil. A missing,
+// unreadable or outdated index is replaced by an empty one, Sync fills it again, as is
+// an index in plaintext when there is a key.
+func Open(path string, key *encrypt.Key) *Index {
+	idx := &Index{path: path, key: key}
+
+	f, err := os.Open(path)
+	if err == nil {
+		var r io.Reader = f
+		if key != nil {
+			r, err = key.NewReader(f)
+		}
+		if err == nil {
+			err = gob.NewDecoder(r).Decode(idx)
+		}
+		_ = f.Close()
+	}
+
+	if err != nil || idx.Version != indexVersion {
+		idx.Version = indexVersion
+		idx.Docs = make(map[string]*document)
+		idx.Postings = make(map[string][]posting)
+	}
+
+	idx.terms = nil
+	return idx
+}
+
+// Save writes the index, replacing the file atomically.
+func (idx *Index) Save() error {
+	if err := os.MkdirAll(filepath.Dir(idx.path), 0700); err != nil {
+		return fmt.Errorf("failed to create index directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(idx.path), ".index-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	var w io.WriteCloser = tmp
+	if idx.key != nil {
+		if w, err = idx.key.NewWriter(tmp); err != nil {
+			_ = tmp.Close()
+			return err
+		}
+	}
+
+	if err = gob.NewEncoder(w).Encode(idx); err == nil && w != tmp {
+		err = w.Close()
+	}
+	if err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to encode index: %w", err)
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), idx.path)
+}
+
+// Add indexes a saved session, replacing the previous version of the same session.
+func (idx *Index) Add(s *session.Session) {
+	id := s.Manifest.ID
+	idx.Remove(id)
+
+	doc := &document{
+		ID:        id,
+		IndexedAt: time.Now(),
+		Title:     s.Manifest.Title,
+		StartedAt: s.Manifest.StartedAt,
+	}
+
+	for _, c := range s.Chunks {
+		if strings.TrimSpace(c.Text) == "" {
+			continue
+		}
+
+		pos := len(doc.Chunks)
+		doc.Chunks = append(doc.Chunks, chunk{Seq: c.Seq, OffsetMS: c.OffsetMS, Text: c.Text})
+
+		counts := make(map[string]int)
+		for _, term := range Tokenize(c.Text) {
+			counts[term]++
+		}
+		// the title makes every chunk of the session match
+		for _, term := range Tokenize(s.Manifest.Title) {
+			counts[term]++
+		}
+
+		for term, n := range counts {
+			idx.Postings[term] = append(idx.Postings[term], posting{Doc: id, Chunk: pos, Count: n})
+		}
+	}
+
+	idx.Docs[id] = doc
+	idx.terms = nil
+}
+
+// Len returns the number of indexed sessions.
+func (idx *Index) Len() int {
+	return len(idx.Docs)
+}
+
+// Remove drops a session from the index.
+func (idx *Index) Remove(id string) {
+	if _, ok := idx.Docs[id]; !ok {
+		return
+	}
+
+	delete(idx.Docs, id)
+	for term, list := range idx.Postings {
+		kept := list[:0]
+		for _, p := range list {
+			if p.Doc != id {
+				kept = append(kept, p)
+			}
+		}
+
+		if len(kept) == 0 {
+			delete(idx.Postings, term)
+		} else {
+			idx.Postings[term] = kept
+		}
+	}
+	idx.terms = nil
+}
+
+// Sync brings the index in line with the saved sessions: the sessions saved since they
+// were indexed are indexed again with load, deleted ones are dropped. It reports whether
+// the index changed.
+func (idx *Index) Sync(entries []session.Entry, load func(id string) (*session.Session, error)) (bool, error) {
+	changed := false
+	present := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		id := e.Manifest.ID
+		present[id] = true
+
+		if doc, ok := idx.Docs[id]; ok && !e.UpdatedAt.After(doc.IndexedAt) {
+			continue
+		}
+
+		s, err := load(id)
+		if err != nil {
+			return changed, err
+		}
+		idx.Add(s)
+		changed = true
+	}
+
+	for id := range idx.Docs {
+		if !present[id] {
+			idx.Remove(id)
+			changed = true
+		}
+	}
+
+	return changed, nil
+}
+
+// Search returns the chunks containing every word of the query, the last word of the
+// query may be the prefix of a longer one. Results are ranked by relevance, then by
+// date, most recent first.
+func (idx *Index) Search(query string, limit int) []Result {
+	words := Tokenize(query)
+	if len(words) == 0 {
+		return nil
+	}
+
+	type key struct {
+		doc   string
+		chunk int
+	}
+	scores := make(map[key]float64)
+	matched := make(map[key][]string)
+	total := float64(idx.chunkCount())
+
+	for i, word := range words {
+		terms := []string{word}
+		if i == len(words)-1 {
+			terms = idx.withPrefix(word)
+		}
+
+		hits := make(map[key]float64)
+		for _, term := range terms {
+			list := idx.Postings[term]
+			idf := math.Log(1 + total/float64(len(list)))
+			for _, p := range list {
+				k := key{p.Doc, p.Chunk}
+				hits[k] += float64(p.Count) * idf
+				matched[k] = append(matched[k], term)
+			}
+		}
+
+		if i == 0 {
+			scores = hits
+			continue
+		}
+		for k := range scores {
+			if _, ok := hits[k]; !ok {
+				delete(scores, k)
+			} else {
+				scores[k] += hits[k]
+			}
+		}
+	}
+
+	results := make([]Result, 0, len(scores))
+	for k, score := range scores {
+		doc := idx.Docs[k.doc]
+		c := doc.Chunks[k.chunk]
+		results = append(results, Result{
+			ID:        doc.ID,
+			Title:     doc.Title,
+			StartedAt: doc.StartedAt,
+			Seq:       c.Seq,
+			Offset:    time.Duration(c.OffsetMS) * time.Millisecond,
+			Text:      c.Text,
+			Terms:     matched[k],
+			Score:     score,
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if !results[i].StartedAt.Equal(results[j].StartedAt) {
+			return results[i].StartedAt.After(results[j].StartedAt)
+		}
+		return results[i].Seq < results[j].Seq
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+func (idx *Index) withPrefix(prefix string) []string {
+	if idx.terms == nil {
+		idx.terms = make([]string, 0, len(idx.Postings))
+		for term := range idx.Postings {
+			idx.terms = append(idx.terms, term)
+		}
+		sort.Strings(idx.terms)
+	}
+
+	var terms []string
+	for i := sort.SearchStrings(idx.terms, prefix); i < len(idx.terms) && strings.HasPrefix(idx.terms[i], prefix); i++ {
+		terms = append(terms, idx.terms[i])
+	}
+	return terms
+}
+
+func (idx *Index) chunkCount() int {
+	n := 0
+	for _, doc := range idx.Docs {
+		n += len(doc.Chunks)
+	}
+	return max(n, 1)
+}
+
+// Tokenize splits text into lower case words.
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
@@ -0,0 +1,75 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Snippet returns the part of text around the first matching word, at most width runes
+// long, with an ellipsis where the text was cut.
+func Snippet(text string, terms []string, width int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) <= width {
+		return text
+	}
+
+	first := 0
+	for _, span := range matches(text, terms) {
+		first = utf8.RuneCountInString(text[:span[0]])
+		break
+	}
+
+	start := max(0, min(first-width/3, len(runes)-width))
+	end := min(len(runes), start+width)
+
+	snippet := string(runes[start:end])
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(runes) {
+		snippet += "…"
+	}
+	return snippet
+}
+
+// Highlight applies mark to the words of text that start with one of the terms.
+func Highlight(text string, terms []string, mark func(string) string) string {
+	var b strings.Builder
+	last := 0
+	for _, span := range matches(text, terms) {
+		b.WriteString(text[last:span[0]])
+		b.WriteString(mark(text[span[0]:span[1]]))
+		last = span[1]
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// matches returns the byte ranges of the words of text starting with one of the terms.
+func matches(text string, terms []string) [][2]int {
+	var spans [][2]int
+	start := -1
+	for i, r := range text + " " {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start < 0 {
+			continue
+		}
+
+		word := strings.ToLower(text[start:i])
+		for _, term := range terms {
+			if strings.HasPrefix(word, term) {
+				spans = append(spans, [2]int{start, i})
+				break
+			}
+		}
+		start = -1
+	}
+	return spans
+}
@@ -0,0 +1,181 @@
+// Package server exposes the application over a local HTTP API, for editor plugins
+// and scripts.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tuanta7/ekko/internal/core"
+)
+
+// DefaultListen is the address used when none is configured, only reachable from this machine.
+const DefaultListen = "127.0.0.1:7777"
+
+type Config struct {
+	// Listen is the address the server listens on, its host name is accepted in the Host
+	// header of the requests besides localhost and the IP addresses.
+	Listen string
+	// FilenameTemplate names the downloaded sessions, see core.RenderFilename.
+	FilenameTemplate string
+	// ChunkDuration is used for the sessions started without one.
+	ChunkDuration time.Duration
+	// Logger reports the sessions saved in the background, nil discards it.
+	Logger *log.Logger
+	// ReadOnly only serves the caption viewer, its status and live events, for when the
+	// sessions are driven by the TUI and the server only shows them. It leaves out the
+	// endpoints controlling sessions and the saved sessions, the viewer may be shared on
+	// the LAN.
+	ReadOnly bool
+}
+
+type Server struct {
+	app *core.Application
+	cfg Config
+	mux *http.ServeMux
+}
+
+func New(app *core.Application, cfg Config) *Server {
+	if cfg.Logger == nil {
+		cfg.Logger = log.New(io.Discard, "", 0)
+	}
+
+	s := &Server{app: app, cfg: cfg, mux: http.NewServeMux()}
+	s.routes()
+	return s
+}
+
+func (s *Server) routes() {
+	s.mux.Handle("GET /", viewerHandler())
+	s.mux.HandleFunc("GET /api/status", s.handleStatus)
+	s.mux.HandleFunc("GET /api/events", s.handleEvents)
+	if s.cfg.ReadOnly {
+		return
+	}
+
+	s.mux.HandleFunc("POST /api/session/start", s.handleStart)
+	s.mux.HandleFunc("POST /api/session/stop", s.handleStop)
+	s.mux.HandleFunc("POST /api/session/pause", s.handlePause)
+	s.mux.HandleFunc("POST /api/session/resume", s.handleResume)
+	s.mux.HandleFunc("POST /api/session/bookmark", s.handleBookmark)
+	s.mux.HandleFunc("GET /api/sessions", s.handleSessions)
+	s.mux.HandleFunc("GET /api/sessions/{id}", s.handleSession)
+}
+
+func (s *Server) Handler() http.Handler {
+	return knownHost(s.cfg.Listen, sameOrigin(s.mux))
+}
+
+// Serve answers requests on the listener until the context is canceled.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	srv := &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		ErrorLog:          s.cfg.Logger,
+		// requests, the event feeds in particular, end with the context
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Listen listens on a TCP address, e.g. 127.0.0.1:7777, or on a Unix socket given as
+// unix:/path/to/socket. The socket is only accessible to the current user.
+func Listen(addr string) (net.Listener, error) {
+	path, ok := strings.CutPrefix(addr, "unix:")
+	if !ok {
+		return net.Listen("tcp", addr)
+	}
+
+	// a socket left by a previous run refuses connections, it is safe to replace
+	if conn, err := net.Dial("unix", path); err == nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("%s is in use by another process", path)
+	}
+	_ = os.Remove(path)
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = os.Chmod(path, 0600); err != nil {
+		_ = ln.Close()
+		return nil, err
+	}
+
+	return ln, nil
+}
+
+// knownHost rejects the requests for other host names than localhost and the one of the
+// listen address. A web page of another site can resolve its own name to 127.0.0.1 to
+// pass sameOrigin, it cannot make the browser send a host name it does not own. IP
+// addresses cannot be rebound.
+func knownHost(listen string, next http.Handler) http.Handler {
+	listenHost, _, err := net.SplitHostPort(listen)
+	if err != nil {
+		listenHost = ""
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			host = r.Host // no port
+		}
+		host = strings.TrimSuffix(strings.ToLower(host), ".")
+
+		known := host == "localhost" || strings.HasSuffix(host, ".localhost") ||
+			net.ParseIP(strings.Trim(host, "[]")) != nil ||
+			(listenHost != "" && strings.EqualFold(host, listenHost))
+		if !known {
+			writeError(w, http.StatusForbidden, fmt.Errorf("unknown host %q", r.Host))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// sameOrigin rejects requests made by web pages of other origins, so a site opened in
+// the browser cannot control the sessions. Scripts send no Origin header.
+func sameOrigin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if origin := r.Header.Get("Origin"); origin != "" {
+			u, err := url.Parse(origin)
+			if err != nil || u.Host != r.Host {
+				writeError(w, http.StatusForbidden, errors.New("cross-origin requests are not allowed"))
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
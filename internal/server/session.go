@@ -0,0 +1,199 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/tuanta7/ekko/internal/core"
+	"github.com/tuanta7/ekko/internal/session"
+)
+
+type startRequest struct {
+	Title string   `json:"title"`
+	Tags  []string `json:"tags"`
+	// ChunkDuration is a Go duration, e.g. 10s.
+	ChunkDuration string `json:"chunk_duration"`
+}
+
+type bookmarkRequest struct {
+	Note string `json:"note"`
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, s.app.Status())
+}
+
+func (s *Server) handleStart(w http.ResponseWriter, r *http.Request) {
+	var req startRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	opts := core.SessionOptions{
+		ChunkDuration: s.cfg.ChunkDuration,
+		Title:         req.Title,
+		Tags:          req.Tags,
+	}
+	if req.ChunkDuration != "" {
+		d, err := time.ParseDuration(req.ChunkDuration)
+		if err != nil || d < time.Second {
+			writeError(w, http.StatusBadRequest, errors.New("chunk_duration must be a duration of at least 1s"))
+			return
+		}
+		opts.ChunkDuration = d
+	}
+
+	stream, err := s.app.Start(opts)
+	if err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	go s.drain(stream)
+
+	writeJSON(w, http.StatusCreated, s.app.Status())
+}
+
+// drain reads the stream of a session started by the API, the events reach the clients
+// through the event feed. A session that ends on its own is saved.
+func (s *Server) drain(stream <-chan core.Event) {
+	for range stream {
+	}
+
+	id, err := s.app.Stop()
+	switch {
+	case errors.Is(err, core.ErrNoSession):
+		// stopped with the API
+	case id == "":
+		s.cfg.Logger.Printf("failed to save session: %v", err)
+	default:
+		s.cfg.Logger.Printf("session %s saved", id)
+		if err != nil {
+			s.cfg.Logger.Printf("warning: %v", err)
+		}
+		s.summarize(id)
+	}
+}
+
+// summarize writes the summary of a saved session when a provider is configured, the
+// clients see it in the saved session once the summary is logged.
+func (s *Server) summarize(id string) {
+	if !s.app.CanSummarize() {
+		return
+	}
+
+	if _, err := s.app.Summarize(context.Background(), id); err != nil {
+		s.cfg.Logger.Printf("failed to summarize session %s: %v", id, err)
+		return
+	}
+	s.cfg.Logger.Printf("session %s summarized", id)
+}
+
+func (s *Server) handleStop(w http.ResponseWriter, _ *http.Request) {
+	id, err := s.app.Stop()
+	if id == "" {
+		writeError(w, statusOf(err), err)
+		return
+	}
+
+	res := map[string]string{"session": id}
+	if err != nil {
+		// saved, but the journal removal, the exports or the indexing failed
+		s.cfg.Logger.Printf("warning: %v", err)
+		res["warning"] = err.Error()
+	}
+
+	go s.summarize(id)
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (s *Server) handlePause(w http.ResponseWriter, _ *http.Request) {
+	if err := s.app.Pause(); err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.app.Status())
+}
+
+func (s *Server) handleResume(w http.ResponseWriter, _ *http.Request) {
+	if err := s.app.Resume(); err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.app.Status())
+}
+
+func (s *Server) handleBookmark(w http.ResponseWriter, r *http.Request) {
+	at := time.Now()
+	var req bookmarkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	b, err := s.app.AddBookmark(at, req.Note)
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, b)
+}
+
+// handleEvents streams the events of the sessions as server-sent events, named after
+// the event type, with the JSON encoded event as data.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	rc := http.NewResponseController(w)
+
+	events, unsubscribe := s.app.Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	// comments keep proxies and idle clients from closing the connection
+	heartbeat := time.NewTicker(15 * time.Second)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case ev := <-events:
+			data, err := json.Marshal(ev)
+			if err != nil {
+				continue
+			}
+			if _, err = io.WriteString(w, "event: "+ev.Type.String()+"\ndata: "+string(data)+"\n\n"); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func statusOf(err error) int {
+	switch {
+	case errors.Is(err, core.ErrNoSession), errors.Is(err, core.ErrPaused), errors.Is(err, core.ErrNotPaused),
+		errors.Is(err, core.ErrTranscribing):
+		return http.StatusConflict
+	case errors.Is(err, session.ErrNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/tuanta7/ekko/internal/core"
+	"github.com/tuanta7/ekko/internal/export"
+)
+
+type sessionEntry struct {
+	ID         string    `json:"id"`
+	Title      string    `json:"title,omitempty"`
+	Tags       []string  `json:"tags,omitempty"`
+	StartedAt  time.Time `json:"started_at"`
+	DurationMS int64     `json:"duration_ms"`
+	Backend    string    `json:"backend"`
+	Model      string    `json:"model"`
+	Chunks     int       `json:"chunks"`
+	// Formats are the values accepted by the format parameter of the download.
+	Formats []string `json:"formats"`
+}
+
+func (s *Server) handleSessions(w http.ResponseWriter, _ *http.Request) {
+	entries, err := s.app.ListSessions()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	list := make([]sessionEntry, 0, len(entries))
+	for _, e := range entries {
+		list = append(list, sessionEntry{
+			ID:         e.Manifest.ID,
+			Title:      e.Manifest.Title,
+			Tags:       e.Manifest.Tags,
+			StartedAt:  e.Manifest.StartedAt,
+			DurationMS: e.Manifest.Duration().Milliseconds(),
+			Backend:    e.Manifest.Backend,
+			Model:      e.Manifest.Model,
+			Chunks:     e.Chunks,
+			Formats:    export.Formats(),
+		})
+	}
+
+	writeJSON(w, http.StatusOK, list)
+}
+
+// handleSession downloads a saved session, as JSON unless the format parameter names
+// another export format.
+func (s *Server) handleSession(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+
+	exporter, err := s.app.Exporter(format)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// IDs only, not prefixes, so a link keeps naming the same session
+	sess, err := s.app.LoadSession(r.PathValue("id"))
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+
+	name := core.RenderFilename(s.cfg.FilenameTemplate, sess.Manifest) + exporter.Ext()
+	w.Header().Set("Content-Type", contentType(exporter.Ext()))
+	if r.URL.Query().Has("download") {
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	}
+
+	if err = exporter.Export(w, sess); err != nil {
+		s.cfg.Logger.Printf("failed to export session %s: %v", sess.Manifest.ID, err)
+	}
+}
+
+func contentType(ext string) string {
+	switch ext {
+	case ".json":
+		return "application/json"
+	case ".md":
+		return "text/markdown; charset=utf-8"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
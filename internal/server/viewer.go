@@ -0,0 +1,20 @@
+package server
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// web holds the caption viewer, a page following the event feed in large text.
+//
+//go:embed web
+var web embed.FS
+
+func viewerHandler() http.Handler {
+	root, err := fs.Sub(web, "web")
+	if err != nil {
+		panic(err) // the directory is embedded at build time
+	}
+	return http.FileServerFS(root)
+}
@@ -0,0 +1,132 @@
+"use strict";
+
+const captions = document.getElementById("captions");
+const stateEl = document.getElementById("state");
+const titleEl = document.getElementById("title");
+const followBtn = document.getElementById("follow");
+const themeSelect = document.getElementById("theme");
+const sizeInput = document.getElementById("size");
+
+// Settings come from the query string, e.g. ?theme=light&size=56, then from the last visit.
+const params = new URLSearchParams(location.search);
+
+function setting(name, fallback) {
+	return params.get(name) || localStorage.getItem("ekko." + name) || fallback;
+}
+
+function applyTheme(theme) {
+	document.documentElement.dataset.theme = theme;
+	themeSelect.value = theme;
+	localStorage.setItem("ekko.theme", theme);
+}
+
+function applySize(size) {
+	document.documentElement.style.setProperty("--caption-size", size + "px");
+	sizeInput.value = size;
+	localStorage.setItem("ekko.size", size);
+}
+
+themeSelect.addEventListener("change", () => applyTheme(themeSelect.value));
+sizeInput.addEventListener("input", () => applySize(sizeInput.value));
+applyTheme(setting("theme", "dark"));
+applySize(setting("size", "40"));
+
+// Auto-scroll follows the live captions until the reader scrolls up.
+let following = true;
+
+function atBottom() {
+	return captions.scrollHeight - captions.scrollTop - captions.clientHeight < 40;
+}
+
+captions.addEventListener("scroll", () => {
+	following = atBottom();
+	followBtn.hidden = following;
+});
+
+followBtn.addEventListener("click", () => {
+	following = true;
+	followBtn.hidden = true;
+	scrollToLive();
+});
+
+function scrollToLive() {
+	if (following) {
+		captions.scrollTop = captions.scrollHeight;
+	}
+}
+
+let pending = null;
+
+function pendingLine() {
+	if (!pending) {
+		pending = document.createElement("p");
+		pending.className = "pending";
+		captions.appendChild(pending);
+	}
+	return pending;
+}
+
+function addLine(text, className) {
+	const p = document.createElement("p");
+	p.textContent = text;
+	if (className) {
+		p.className = className;
+	}
+	captions.appendChild(p);
+	scrollToLive();
+}
+
+function setState(state, manifest) {
+	stateEl.textContent = state;
+	stateEl.className = "state " + state;
+	titleEl.textContent = manifest && manifest.title ? manifest.title : "";
+}
+
+function refreshStatus() {
+	fetch("api/status")
+		.then((res) => res.json())
+		.then((st) => setState(st.state, st.session))
+		.catch(() => setState("offline"));
+}
+
+const handlers = {
+	session_start() {
+		captions.replaceChildren();
+		pending = null;
+		refreshStatus();
+	},
+	partial(ev) {
+		pendingLine().textContent += ev.text;
+		scrollToLive();
+	},
+	chunk(ev) {
+		if (pending) {
+			pending.remove();
+			pending = null;
+		}
+		if (ev.text && ev.text.trim()) {
+			const paragraph = ev.chunk && ev.chunk.paragraph && captions.childElementCount > 0;
+			addLine(ev.text.trim(), paragraph ? "paragraph" : "");
+		}
+	},
+	error(ev) {
+		addLine("⚠ " + ev.error, "error");
+	},
+	status(ev) {
+		if (ev.text === "Paused") {
+			addLine("⏸ paused", "marker");
+		}
+		refreshStatus();
+	},
+	session_end() {
+		addLine("Session ended", "marker");
+		setState("idle");
+	},
+};
+
+const source = new EventSource("api/events");
+for (const [type, handle] of Object.entries(handlers)) {
+	source.addEventListener(type, (e) => handle(JSON.parse(e.data)));
+}
+source.addEventListener("open", refreshStatus);
+source.addEventListener("error", () => setState("offline"));
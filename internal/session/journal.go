@@ -0,0 +1,251 @@
+package session
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+
+	"github.com/tuanta7/ekko/internal/encrypt"
+)
+
+const journalExt = ".jsonl"
+
+var ErrJournalClosed = errors.New("journal closed")
+
+// record is a single line of the journal. The manifest may be written several times,
+// the last one wins.
+type record struct {
+	Kind     string    `json:"kind"`
+	Manifest *Manifest `json:"manifest,omitempty"`
+	Chunk    *Chunk    `json:"chunk,omitempty"`
+	Pause    *Pause    `json:"pause,omitempty"`
+	Bookmark *Bookmark `json:"bookmark,omitempty"`
+}
+
+const (
+	recordManifest = "manifest"
+	recordChunk    = "chunk"
+	recordPause    = "pause" // written when the pause starts and again when it ends
+	recordBookmark = "bookmark"
+)
+
+// Journal is an append-only JSON lines log of a session in progress. Every record is
+// synced to disk before Append returns, so an interrupted session can be rebuilt with
+// ReadJournal. The file is exclusively locked while the journal is open. With a key,
+// every line is sealed.
+type Journal struct {
+	mu     sync.Mutex
+	path   string
+	file   *os.File
+	key    *encrypt.Key
+	closed bool
+}
+
+func JournalPath(dir, id string) string {
+	return filepath.Join(dir, id+journalExt)
+}
+
+// CreateJournal creates the journal of a session, key seals its lines when not nil.
+func CreateJournal(dir string, m Manifest, key *encrypt.Key) (*Journal, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create journal directory: %w", err)
+	}
+
+	path := JournalPath(dir, m.ID)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create journal: %w", err)
+	}
+
+	if err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("failed to lock journal: %w", err)
+	}
+
+	j := &Journal{path: path, file: f, key: key}
+	if err = j.AppendManifest(m); err != nil {
+		_ = j.Close()
+		return nil, err
+	}
+
+	return j, nil
+}
+
+func (j *Journal) Path() string {
+	return j.path
+}
+
+func (j *Journal) AppendManifest(m Manifest) error {
+	return j.append(record{Kind: recordManifest, Manifest: &m})
+}
+
+func (j *Journal) AppendChunk(c Chunk) error {
+	return j.append(record{Kind: recordChunk, Chunk: &c})
+}
+
+func (j *Journal) AppendPause(p Pause) error {
+	return j.append(record{Kind: recordPause, Pause: &p})
+}
+
+func (j *Journal) AppendBookmark(b Bookmark) error {
+	return j.append(record{Kind: recordBookmark, Bookmark: &b})
+}
+
+func (j *Journal) append(r record) error {
+	line, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if j.key != nil {
+		line = []byte(j.key.SealString(string(line)))
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.closed {
+		return ErrJournalClosed
+	}
+
+	if _, err = j.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+
+	return j.file.Sync()
+}
+
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.closed {
+		return nil
+	}
+
+	j.closed = true
+	return j.file.Close() // closing the descriptor releases the lock
+}
+
+// Remove closes and deletes the journal, once the session has been saved.
+func (j *Journal) Remove() error {
+	if err := j.Close(); err != nil {
+		return err
+	}
+	return os.Remove(j.path)
+}
+
+// FindJournals lists the journals in dir that are not held by a running session.
+func FindJournals(dir string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+journalExt))
+	if err != nil {
+		return nil, err
+	}
+
+	var free []string
+	for _, path := range paths {
+		if locked, err := isLocked(path); err == nil && !locked {
+			free = append(free, path)
+		}
+	}
+
+	return free, nil
+}
+
+// ReadJournal rebuilds a session from a journal. A truncated last line, typically
+// left by a crash during a write, is ignored, any other unreadable line is an error so
+// the chunks after it are not silently lost. The sealed lines are opened with key, they
+// fail with encrypt.ErrLocked when it is nil.
+func ReadJournal(path string, key *encrypt.Key) (*Session, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var s *Session
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	var bad error // the error of an unreadable line, ignored if it is the last one
+	for n := 1; scanner.Scan(); n++ {
+		if bad != nil {
+			return nil, fmt.Errorf("%s: %w", path, bad)
+		}
+
+		line := scanner.Text()
+		if encrypt.IsSealed(line) {
+			if key == nil {
+				return nil, fmt.Errorf("%s: %w", path, encrypt.ErrLocked)
+			}
+			if line, err = key.OpenString(line); err != nil {
+				bad = fmt.Errorf("corrupt journal line %d: %w", n, err)
+				continue
+			}
+		}
+
+		var r record
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			bad = fmt.Errorf("corrupt journal line %d: %w", n, err)
+			continue
+		}
+
+		switch r.Kind {
+		case recordManifest:
+			if r.Manifest == nil {
+				continue
+			}
+			if s == nil {
+				s = New()
+			}
+			s.Manifest = *r.Manifest
+		case recordChunk:
+			if s == nil || r.Chunk == nil {
+				continue
+			}
+			s.Append(*r.Chunk)
+		case recordPause:
+			if s == nil || r.Pause == nil {
+				continue
+			}
+			s.SetPause(*r.Pause)
+		case recordBookmark:
+			if s == nil || r.Bookmark == nil {
+				continue
+			}
+			s.AddBookmark(*r.Bookmark)
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	if s == nil {
+		return nil, fmt.Errorf("%s: journal has no manifest", path)
+	}
+
+	return s, nil
+}
+
+func isLocked(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	if err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return true, nil
+		}
+		return false, err
+	}
+
+	_ = syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+	return false, nil
+}
@@ -0,0 +1,144 @@
+package session
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/tuanta7/ekko/internal/encrypt"
+)
+
+// writeJournal writes a journal of three chunks and returns its path and its lines.
+func writeJournal(t *testing.T, key *encrypt.Key) (string, []string) {
+	t.Helper()
+	j, err := CreateJournal(t.TempDir(), Manifest{ID: "20261019-100000-abcdef", Title: "Budget"}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, text := range []string{"let us plan", "the budget", "Sam sends it"} {
+		if err = j.AppendChunk(Chunk{Seq: i + 1, OffsetMS: int64(i) * 10_000, Text: text}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = j.AppendBookmark(Bookmark{OffsetMS: 12_000, Note: "action item"}); err != nil {
+		t.Fatal(err)
+	}
+	if err = j.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(j.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return j.Path(), strings.SplitAfter(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+func rewrite(t *testing.T, path string, lines []string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "")), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadJournal(t *testing.T) {
+	path, _ := writeJournal(t, nil)
+
+	s, err := ReadJournal(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Manifest.Title != "Budget" {
+		t.Errorf("title = %q, want Budget", s.Manifest.Title)
+	}
+	if len(s.Chunks) != 3 || s.Chunks[2].Text != "Sam sends it" {
+		t.Errorf("chunks = %+v, want the 3 chunks", s.Chunks)
+	}
+	if len(s.Bookmarks) != 1 || s.Bookmarks[0].Note != "action item" {
+		t.Errorf("bookmarks = %+v", s.Bookmarks)
+	}
+}
+
+func TestReadJournalTruncatedLastLine(t *testing.T) {
+	path, lines := writeJournal(t, nil)
+	// a crash during the write of the bookmark
+	last := len(lines) - 1
+	lines[last] = lines[last][:len(lines[last])/2]
+	rewrite(t, path, lines)
+
+	s, err := ReadJournal(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Chunks) != 3 {
+		t.Errorf("got %d chunks, want 3", len(s.Chunks))
+	}
+	if len(s.Bookmarks) != 0 {
+		t.Errorf("bookmarks = %+v, want the truncated one ignored", s.Bookmarks)
+	}
+}
+
+func TestReadJournalCorruptMiddleLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		corrupt func(line string) string
+	}{
+		{"truncated", func(line string) string { return line[:len(line)/2] + "\n" }},
+		{"garbage", func(string) string { return "\x00\x00\x00\n" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, lines := writeJournal(t, nil)
+			lines[2] = tt.corrupt(lines[2]) // the second chunk
+			rewrite(t, path, lines)
+
+			if s, err := ReadJournal(path, nil); err == nil {
+				t.Errorf("read %d chunks of a journal with a corrupt line, want an error", len(s.Chunks))
+			} else if !strings.Contains(err.Error(), "line 3") {
+				t.Errorf("error = %v, want it to name line 3", err)
+			}
+		})
+	}
+}
+
+func TestReadJournalSealed(t *testing.T) {
+	key, err := encrypt.DeriveKey("passphrase", bytes.Repeat([]byte{1}, encrypt.SaltSize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, lines := writeJournal(t, key)
+
+	data, _ := os.ReadFile(path)
+	if bytes.Contains(data, []byte("budget")) {
+		t.Error("the sealed journal holds the transcript in plaintext")
+	}
+
+	s, err := ReadJournal(path, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Chunks) != 3 || s.Chunks[1].Text != "the budget" {
+		t.Errorf("chunks = %+v, want the 3 chunks", s.Chunks)
+	}
+
+	if _, err = ReadJournal(path, nil); !errors.Is(err, encrypt.ErrLocked) {
+		t.Errorf("error without a key = %v, want ErrLocked", err)
+	}
+
+	// a truncated sealed line is tolerated at the end only
+	last := len(lines) - 1
+	lines[last] = lines[last][:len(lines[last])-10]
+	rewrite(t, path, lines)
+	if s, err = ReadJournal(path, key); err != nil || len(s.Chunks) != 3 {
+		t.Errorf("truncated last sealed line: %v", err)
+	}
+
+	lines[1] = lines[1][:len(lines[1])-10] + "\n"
+	rewrite(t, path, lines)
+	if _, err = ReadJournal(path, key); err == nil {
+		t.Error("read a journal with a corrupt sealed line, want an error")
+	}
+}
@@ -0,0 +1,16 @@
+package session
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrNotFound = errors.New("session not found")
+
+// Entry is a saved session without its transcript, as listed by a store.
+type Entry struct {
+	Manifest Manifest
+	Chunks   int
+	// UpdatedAt is when the session was last saved.
+	UpdatedAt time.Time
+}
@@ -0,0 +1,399 @@
+package session
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FormatVersion is the version written by Marshal. Version 1 is the legacy
+// format, a flat object keyed by unix timestamps.
+const FormatVersion = 2
+
+var ErrUnsupportedVersion = errors.New("unsupported session format version")
+
+// ErrNotSession is returned for JSON data that is not a session in any known format.
+var ErrNotSession = errors.New("not a session")
+
+type Chunk struct {
+	// Seq is the position of the chunk in the recording, starting at 1.
+	Seq int `json:"seq"`
+	// OffsetMS is the time between the session start and the start of the chunk,
+	// measured with the monotonic clock.
+	OffsetMS  int64     `json:"offset_ms"`
+	Timestamp time.Time `json:"timestamp"`
+	Text      string    `json:"text"`
+	Error     string    `json:"error,omitempty"`
+	// Speaker names who spoke, when the backend tells speakers apart.
+	Speaker string `json:"speaker,omitempty"`
+	// RawText is the text as transcribed, when Text was formatted.
+	RawText string `json:"raw_text,omitempty"`
+	// Paragraph is set when the formatted chunk starts a paragraph.
+	Paragraph bool `json:"paragraph,omitempty"`
+}
+
+func (c Chunk) Offset() time.Duration {
+	return time.Duration(c.OffsetMS) * time.Millisecond
+}
+
+// FormatOffset formats an offset in the recording to the second, e.g. 01:02:03.
+func FormatOffset(d time.Duration) string {
+	d = d.Round(time.Second)
+	return fmt.Sprintf("%02d:%02d:%02d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60)
+}
+
+// Raw returns the text as transcribed, before it was formatted.
+func (c Chunk) Raw() string {
+	if c.RawText != "" {
+		return c.RawText
+	}
+	return c.Text
+}
+
+// Manifest is the session header, describing how and when it was recorded.
+type Manifest struct {
+	ID              string    `json:"id"`
+	Title           string    `json:"title,omitempty"`
+	Tags            []string  `json:"tags,omitempty"`
+	StartedAt       time.Time `json:"started_at"`
+	EndedAt         time.Time `json:"ended_at"`
+	Backend         string    `json:"backend"`
+	Model           string    `json:"model"`
+	Language        string    `json:"language,omitempty"`
+	Source          string    `json:"source,omitempty"`
+	ChunkDurationMS int64     `json:"chunk_duration_ms"`
+	AppVersion      string    `json:"app_version"`
+	Audio           *Audio    `json:"audio,omitempty"`
+	// Revision counts transcriptions of the same recording, the live one is revision 1.
+	Revision int `json:"revision,omitempty"`
+	// ParentID is the session this revision was transcribed from.
+	ParentID      string    `json:"parent_id,omitempty"`
+	TranscribedAt time.Time `json:"transcribed_at,omitempty"`
+}
+
+// RevisionNumber returns the revision, treating sessions saved before revisions existed as the first one.
+func (m Manifest) RevisionNumber() int {
+	if m.Revision < 1 {
+		return 1
+	}
+	return m.Revision
+}
+
+// Audio references the retained recording of a session.
+type Audio struct {
+	// File is relative to the output directory.
+	File     string         `json:"file"`
+	Format   string         `json:"format"`
+	Segments []AudioSegment `json:"segments"`
+}
+
+// AudioSegment locates the audio of a chunk in the retained recording.
+type AudioSegment struct {
+	Seq        int   `json:"seq"`
+	OffsetMS   int64 `json:"offset_ms"`
+	DurationMS int64 `json:"duration_ms"`
+}
+
+func (s AudioSegment) Offset() time.Duration {
+	return time.Duration(s.OffsetMS) * time.Millisecond
+}
+
+func (s AudioSegment) Duration() time.Duration {
+	return time.Duration(s.DurationMS) * time.Millisecond
+}
+
+func (m Manifest) ChunkDuration() time.Duration {
+	return time.Duration(m.ChunkDurationMS) * time.Millisecond
+}
+
+// Duration returns the wall time between the start and the end of the session.
+func (m Manifest) Duration() time.Duration {
+	if m.EndedAt.IsZero() {
+		return 0
+	}
+	return m.EndedAt.Sub(m.StartedAt)
+}
+
+// Pause is a gap in the recording, the offsets are relative to the session start
+// like the chunk offsets.
+type Pause struct {
+	StartMS int64 `json:"start_ms"`
+	// EndMS is zero while the session is paused.
+	EndMS int64 `json:"end_ms,omitempty"`
+}
+
+func (p Pause) Start() time.Duration {
+	return time.Duration(p.StartMS) * time.Millisecond
+}
+
+func (p Pause) End() time.Duration {
+	return time.Duration(p.EndMS) * time.Millisecond
+}
+
+// Duration returns the length of the pause, zero while it is not over.
+func (p Pause) Duration() time.Duration {
+	if p.EndMS == 0 {
+		return 0
+	}
+	return p.End() - p.Start()
+}
+
+// Bookmark flags a moment of the session, e.g. an action item, with an optional note.
+type Bookmark struct {
+	// OffsetMS is the time between the session start and the bookmark, like the chunk offsets.
+	OffsetMS  int64     `json:"offset_ms"`
+	Timestamp time.Time `json:"timestamp"`
+	Note      string    `json:"note,omitempty"`
+}
+
+func (b Bookmark) Offset() time.Duration {
+	return time.Duration(b.OffsetMS) * time.Millisecond
+}
+
+type Session struct {
+	Version   int        `json:"version"`
+	Manifest  Manifest   `json:"manifest"`
+	Chunks    []Chunk    `json:"chunks"`
+	Pauses    []Pause    `json:"pauses,omitempty"`
+	Bookmarks []Bookmark `json:"bookmarks,omitempty"`
+	Summary   *Summary   `json:"summary,omitempty"`
+}
+
+func New() *Session {
+	return &Session{
+		Version: FormatVersion,
+		Manifest: Manifest{
+			ID: NewID(),
+		},
+		Chunks: []Chunk{},
+	}
+}
+
+// ParseTags splits a comma separated list of tags.
+func ParseTags(raw string) []string {
+	var tags []string
+	for _, t := range strings.Split(raw, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+// NewID returns a sortable, unique session identifier.
+func NewID() string {
+	suffix := make([]byte, 3)
+	_, _ = rand.Read(suffix)
+	return time.Now().Format("20060102-150405") + "-" + hex.EncodeToString(suffix)
+}
+
+// Append adds a chunk and keeps the chunks ordered by sequence number.
+func (s *Session) Append(c Chunk) {
+	s.Chunks = append(s.Chunks, c)
+	sort.SliceStable(s.Chunks, func(i, j int) bool {
+		return s.Chunks[i].Seq < s.Chunks[j].Seq
+	})
+}
+
+// StartsParagraph reports whether the chunk appended next starts a paragraph: it is the
+// first one, or follows a silent or failed chunk, a pause, or a gap of at least gap since
+// the end of the chunk before it.
+func (s *Session) StartsParagraph(c Chunk, gap time.Duration) bool {
+	if len(s.Chunks) == 0 {
+		return true
+	}
+
+	prev := s.Chunks[len(s.Chunks)-1]
+	if strings.TrimSpace(prev.Text) == "" || prev.Error != "" {
+		return true
+	}
+
+	for _, p := range s.Pauses {
+		if p.StartMS >= prev.OffsetMS && p.StartMS < c.OffsetMS {
+			return true
+		}
+	}
+
+	return c.Offset()-prev.Offset()-s.Manifest.ChunkDuration() >= gap
+}
+
+// SetPause adds a pause, or replaces the one with the same start when it ends.
+func (s *Session) SetPause(p Pause) {
+	for i := range s.Pauses {
+		if s.Pauses[i].StartMS == p.StartMS {
+			s.Pauses[i] = p
+			return
+		}
+	}
+
+	s.Pauses = append(s.Pauses, p)
+	sort.Slice(s.Pauses, func(i, j int) bool {
+		return s.Pauses[i].StartMS < s.Pauses[j].StartMS
+	})
+}
+
+// AddBookmark adds a bookmark and keeps the bookmarks ordered by offset.
+func (s *Session) AddBookmark(b Bookmark) {
+	s.Bookmarks = append(s.Bookmarks, b)
+	sort.SliceStable(s.Bookmarks, func(i, j int) bool {
+		return s.Bookmarks[i].OffsetMS < s.Bookmarks[j].OffsetMS
+	})
+}
+
+// Clone returns a deep copy of the session, which can be read while the session is
+// still being appended to.
+func (s *Session) Clone() *Session {
+	c := *s
+	c.Manifest.Tags = slices.Clone(s.Manifest.Tags)
+	if s.Manifest.Audio != nil {
+		audio := *s.Manifest.Audio
+		audio.Segments = slices.Clone(audio.Segments)
+		c.Manifest.Audio = &audio
+	}
+	c.Chunks = slices.Clone(s.Chunks)
+	c.Pauses = slices.Clone(s.Pauses)
+	c.Bookmarks = slices.Clone(s.Bookmarks)
+	if s.Summary != nil {
+		sum := *s.Summary
+		sum.Decisions = slices.Clone(sum.Decisions)
+		sum.ActionItems = slices.Clone(sum.ActionItems)
+		c.Summary = &sum
+	}
+	return &c
+}
+
+func (s *Session) Marshal() ([]byte, error) {
+	s.Version = FormatVersion
+	return json.MarshalIndent(s, "", "\t")
+}
+
+func Load(path string) (*Session, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := Unmarshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return s, nil
+}
+
+// Unmarshal decodes a session in any known format version.
+func Unmarshal(data []byte) (*Session, error) {
+	var header struct {
+		Version json.RawMessage `json:"version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return nil, ErrNotSession // e.g. an array
+		}
+		return nil, err
+	}
+
+	if header.Version == nil {
+		return unmarshalLegacy(data)
+	}
+
+	var version int
+	if err := json.Unmarshal(header.Version, &version); err != nil {
+		return nil, fmt.Errorf("invalid version: %w", err)
+	}
+
+	if version != FormatVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, version)
+	}
+
+	s := New()
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// unmarshalLegacy reads version 1 files. Those stored errors as "{}" because the
+// error interface has no exported fields, so only their presence is recovered. Other
+// JSON objects, e.g. the settings of another tool, fail with ErrNotSession: a legacy
+// session is keyed by unix timestamps and has a chunk with a text or a timestamp.
+func unmarshalLegacy(data []byte) (*Session, error) {
+	var legacy map[string]struct {
+		Timestamp *int64          `json:"timestamp"`
+		Text      *string         `json:"text"`
+		Error     json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return nil, ErrNotSession // e.g. an object of strings
+	}
+
+	chunks := 0
+	for k, entry := range legacy {
+		if _, err := strconv.ParseInt(k, 10, 64); err != nil {
+			return nil, fmt.Errorf("%w: unexpected key %q", ErrNotSession, k)
+		}
+		if entry.Timestamp != nil || entry.Text != nil {
+			chunks++
+		}
+	}
+	if chunks == 0 {
+		return nil, fmt.Errorf("%w: no transcript", ErrNotSession)
+	}
+
+	keys := make([]string, 0, len(legacy))
+	for k := range legacy {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		ki, _ := strconv.ParseInt(keys[i], 10, 64)
+		kj, _ := strconv.ParseInt(keys[j], 10, 64)
+		return ki < kj
+	})
+
+	s := New()
+	s.Manifest.Backend = "unknown"
+	var first int64
+	for i, k := range keys {
+		entry := legacy[k]
+		// the key is when the chunk was transcribed, close enough without a timestamp
+		timestamp, _ := strconv.ParseInt(k, 10, 64)
+		if entry.Timestamp != nil {
+			timestamp = *entry.Timestamp
+		}
+		if i == 0 {
+			first = timestamp
+		}
+
+		c := Chunk{
+			Seq:       i + 1,
+			OffsetMS:  (timestamp - first) * 1000,
+			Timestamp: time.Unix(timestamp, 0),
+		}
+		if entry.Text != nil {
+			c.Text = *entry.Text
+		}
+		if len(entry.Error) > 0 && string(entry.Error) != "null" {
+			c.Error = "unknown error (not preserved by the legacy format)"
+		}
+		s.Chunks = append(s.Chunks, c)
+	}
+
+	if len(s.Chunks) > 0 {
+		// keep the identifier stable across loads of the same file
+		s.Manifest.ID = fmt.Sprintf("legacy-%d", first)
+		s.Manifest.StartedAt = s.Chunks[0].Timestamp
+		s.Manifest.EndedAt = s.Chunks[len(s.Chunks)-1].Timestamp
+	}
+
+	return s, nil
+}
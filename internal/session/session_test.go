@@ -0,0 +1,168 @@
+package session
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadLegacy(t *testing.T) {
+	s, err := Load(filepath.Join("testdata", "transcript-v1.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s.Version != FormatVersion {
+		t.Errorf("version = %d, want %d", s.Version, FormatVersion)
+	}
+	if s.Manifest.ID != "legacy-1760868000" {
+		t.Errorf("id = %q, want legacy-1760868000", s.Manifest.ID)
+	}
+	if s.Manifest.Backend != "unknown" {
+		t.Errorf("backend = %q, want unknown", s.Manifest.Backend)
+	}
+	if !s.Manifest.StartedAt.Equal(time.Unix(1760868000, 0)) || !s.Manifest.EndedAt.Equal(time.Unix(1760868015, 0)) {
+		t.Errorf("started at %v, ended at %v", s.Manifest.StartedAt, s.Manifest.EndedAt)
+	}
+
+	want := []struct {
+		offset time.Duration
+		text   string
+		failed bool
+	}{
+		{0, "let us plan the budget", false},
+		{10 * time.Second, "", true},
+		{15 * time.Second, "Sam sends it on Friday", true},
+	}
+	if len(s.Chunks) != len(want) {
+		t.Fatalf("got %d chunks, want %d", len(s.Chunks), len(want))
+	}
+	for i, w := range want {
+		c := s.Chunks[i]
+		if c.Seq != i+1 || c.Offset() != w.offset || c.Text != w.text || (c.Error != "") != w.failed {
+			t.Errorf("chunk %d = %+v, want offset %v, text %q, failed %v", i, c, w.offset, w.text, w.failed)
+		}
+	}
+
+	// the same file keeps its identifier
+	again, err := Load(filepath.Join("testdata", "transcript-v1.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Manifest.ID != s.Manifest.ID {
+		t.Errorf("id = %q, then %q", s.Manifest.ID, again.Manifest.ID)
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	s := New()
+	s.Manifest.Title = "Budget"
+	s.Append(Chunk{Seq: 1, Text: "let us plan the budget"})
+	data, err := s.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Unmarshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Manifest.ID != s.Manifest.ID || got.Manifest.Title != "Budget" || len(got.Chunks) != 1 {
+		t.Errorf("Unmarshal = %+v, want %+v", got, s)
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		target error
+		want   string
+	}{
+		{"newer version", `{"version": 3, "manifest": {}, "chunks": []}`, ErrUnsupportedVersion, "unsupported session format version: 3"},
+		{"version only", `{"version": 3}`, ErrUnsupportedVersion, ""},
+		{"invalid version", `{"version": "2", "chunks": []}`, nil, "invalid version"},
+		{"not json", `let us plan`, nil, "invalid character"},
+		// other JSON files of the output directory
+		{"array", `[1, 2]`, ErrNotSession, ""},
+		{"empty object", `{}`, ErrNotSession, ""},
+		{"settings", `{"theme": "dark"}`, ErrNotSession, ""},
+		{"nested settings", `{"editor": {"font": 12, "text": "mono"}}`, ErrNotSession, ""},
+		{"objects without transcript", `{"1760868000": {"font": 12}}`, ErrNotSession, ""},
+		{"failed chunks only", `{"1760868000": {"error": {}}}`, ErrNotSession, ""},
+		{"legacy entry of a string", `{"1760868000": "let us plan"}`, ErrNotSession, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Unmarshal([]byte(tt.data))
+			if err == nil {
+				t.Fatal("no error")
+			}
+			if tt.target != nil && !errors.Is(err, tt.target) {
+				t.Errorf("error = %v, want %v", err, tt.target)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshalLegacyWithoutTimestamp(t *testing.T) {
+	s, err := Unmarshal([]byte(`{"1760868010": {"text": "the budget"}, "1760868000": {"text": "let us plan"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Chunks) != 2 || s.Chunks[0].Text != "let us plan" || s.Chunks[1].Offset() != 10*time.Second {
+		t.Errorf("chunks = %+v, want them placed by their keys", s.Chunks)
+	}
+}
+
+func TestFormatOffset(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "00:00:00"},
+		{1499 * time.Millisecond, "00:00:01"},
+		{1500 * time.Millisecond, "00:00:02"},
+		{59*time.Minute + 59*time.Second + 600*time.Millisecond, "01:00:00"},
+		{25*time.Hour + 2*time.Minute + 3*time.Second, "25:02:03"},
+	}
+	for _, tt := range tests {
+		if got := FormatOffset(tt.d); got != tt.want {
+			t.Errorf("FormatOffset(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestClone(t *testing.T) {
+	s := New()
+	s.Manifest.Tags = []string{"budget"}
+	s.Manifest.Audio = &Audio{File: "audio/x.opus", Segments: []AudioSegment{{Seq: 1}}}
+	s.Append(Chunk{Seq: 2, Text: "the budget"})
+	s.SetPause(Pause{StartMS: 1000})
+	s.AddBookmark(Bookmark{OffsetMS: 2000})
+	s.Summary = &Summary{Decisions: []string{"ship"}}
+
+	c := s.Clone()
+	s.Append(Chunk{Seq: 1, Text: "let us plan"})
+	s.Manifest.Tags[0] = "changed"
+	s.Manifest.Audio.Segments[0].Seq = 9
+	s.SetPause(Pause{StartMS: 1000, EndMS: 1500})
+	s.Bookmarks[0].Note = "changed"
+	s.Summary.Decisions[0] = "changed"
+
+	if len(c.Chunks) != 1 || c.Chunks[0].Seq != 2 {
+		t.Errorf("chunks = %+v, want the chunk 2 only", c.Chunks)
+	}
+	if c.Manifest.Tags[0] != "budget" || c.Manifest.Audio.Segments[0].Seq != 1 {
+		t.Errorf("manifest = %+v, changed with the original", c.Manifest)
+	}
+	if c.Pauses[0].EndMS != 0 || c.Bookmarks[0].Note != "" || c.Summary.Decisions[0] != "ship" {
+		t.Errorf("clone = %+v, changed with the original", c)
+	}
+}
@@ -0,0 +1,20 @@
+package session
+
+import "time"
+
+// Summary is the digest of a session written by a language model once it is saved.
+type Summary struct {
+	Text        string       `json:"text"`
+	Decisions   []string     `json:"decisions,omitempty"`
+	ActionItems []ActionItem `json:"action_items,omitempty"`
+	// Model names the provider and model that wrote the summary, e.g. gemini/gemini-2.0-flash.
+	Model     string    `json:"model,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ActionItem is a task agreed on during the session.
+type ActionItem struct {
+	Task string `json:"task"`
+	// Owner is who takes the task, empty when nobody was named.
+	Owner string `json:"owner,omitempty"`
+}
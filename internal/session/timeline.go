@@ -0,0 +1,57 @@
+package session
+
+import "time"
+
+// TimelineItem is an item of the session timeline, exactly one of its fields is set.
+type TimelineItem struct {
+	Chunk    *Chunk
+	Pause    *Pause
+	Bookmark *Bookmark
+}
+
+// Offset returns the time between the session start and the item.
+func (t TimelineItem) Offset() time.Duration {
+	switch {
+	case t.Chunk != nil:
+		return t.Chunk.Offset()
+	case t.Pause != nil:
+		return t.Pause.Start()
+	case t.Bookmark != nil:
+		return t.Bookmark.Offset()
+	default:
+		return 0
+	}
+}
+
+// Timeline returns the chunks in order, with the pauses and the bookmarks placed before
+// the first chunk recorded after them. A bookmark dropped while a chunk is recorded thus
+// follows the text it refers to.
+func (s *Session) Timeline() []TimelineItem {
+	items := make([]TimelineItem, 0, len(s.Chunks)+len(s.Pauses)+len(s.Bookmarks))
+	pauses, bookmarks := s.Pauses, s.Bookmarks
+
+	flush := func(before time.Duration, all bool) {
+		for {
+			pause := len(pauses) > 0 && (all || pauses[0].Start() <= before)
+			bookmark := len(bookmarks) > 0 && (all || bookmarks[0].Offset() <= before)
+			switch {
+			case pause && (!bookmark || pauses[0].Start() <= bookmarks[0].Offset()):
+				items = append(items, TimelineItem{Pause: &pauses[0]})
+				pauses = pauses[1:]
+			case bookmark:
+				items = append(items, TimelineItem{Bookmark: &bookmarks[0]})
+				bookmarks = bookmarks[1:]
+			default:
+				return
+			}
+		}
+	}
+
+	for i := range s.Chunks {
+		flush(s.Chunks[i].Offset(), false)
+		items = append(items, TimelineItem{Chunk: &s.Chunks[i]})
+	}
+	flush(0, true)
+
+	return items
+}
@@ -0,0 +1,137 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/tuanta7/ekko/internal/encrypt"
+)
+
+// verifierText is sealed with the key when the store is encrypted, opening it tells a
+// wrong passphrase apart.
+const verifierText = "ekko"
+
+// Encrypted reports whether the sessions are encrypted. They can then only be read and
+// saved once the store is unlocked.
+func (st *SQLite) Encrypted() bool {
+	return st.encrypted
+}
+
+// Key returns the key of the encrypted sessions, nil until the store is unlocked.
+func (st *SQLite) Key() *encrypt.Key {
+	return st.key
+}
+
+// Unlock derives the key of the encrypted sessions from the passphrase, it returns
+// encrypt.ErrWrongPassphrase when it is not the one they were encrypted with. It must
+// be called before the store is used.
+func (st *SQLite) Unlock(passphrase string) error {
+	if !st.encrypted {
+		return errors.New("the sessions are not encrypted")
+	}
+
+	var salt []byte
+	var verifier string
+	if err := st.db.QueryRow(`SELECT salt, verifier FROM encryption`).Scan(&salt, &verifier); err != nil {
+		return err
+	}
+
+	key, err := encrypt.DeriveKey(passphrase, salt)
+	if err != nil {
+		return err
+	}
+	if text, err := key.OpenString(verifier); err != nil || text != verifierText {
+		return encrypt.ErrWrongPassphrase
+	}
+
+	st.key = key
+	return nil
+}
+
+// Encrypt encrypts the saved sessions, and the ones saved from then on, with a key
+// derived from the passphrase. The plaintext left in the free pages of the database is
+// erased. Encrypting an encrypted store unlocks it.
+func (st *SQLite) Encrypt(passphrase string) error {
+	if st.encrypted {
+		return st.Unlock(passphrase)
+	}
+
+	salt, err := encrypt.NewSalt()
+	if err != nil {
+		return err
+	}
+	key, err := encrypt.DeriveKey(passphrase, salt)
+	if err != nil {
+		return err
+	}
+
+	entries, err := st.List()
+	if err != nil {
+		return err
+	}
+
+	st.key = key
+	err = st.inTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`INSERT INTO encryption (id, salt, verifier) VALUES (1, ?, ?)`, salt, key.SealString(verifierText))
+		if err != nil {
+			return err
+		}
+
+		for _, e := range entries {
+			// read outside the transaction, the sessions are still in plaintext there
+			s, err := st.Load(e.Manifest.ID)
+			if err != nil {
+				return err
+			}
+			if err = st.save(tx, s); err != nil {
+				return fmt.Errorf("session %s: %w", e.Manifest.ID, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		st.key = nil
+		return fmt.Errorf("failed to encrypt the sessions: %w", err)
+	}
+	st.encrypted = true
+
+	// VACUUM rewrites the database without its free pages, the checkpoint empties the
+	// WAL file, both may still hold the plaintext
+	if _, err = st.db.Exec(`VACUUM`); err != nil {
+		return fmt.Errorf("failed to erase the plaintext sessions: %w", err)
+	}
+	if _, err = st.db.Exec(`PRAGMA wal_checkpoint(TRUNCATE)`); err != nil {
+		return fmt.Errorf("failed to erase the plaintext sessions: %w", err)
+	}
+
+	return nil
+}
+
+// seal seals a text of a session once the store is encrypted.
+func (st *SQLite) seal(text string) string {
+	if st.key == nil {
+		return text
+	}
+	return st.key.SealString(text)
+}
+
+// open opens the sealed texts in place, the ones saved before the store was encrypted
+// are read as they are.
+func (st *SQLite) open(texts ...*string) error {
+	for _, text := range texts {
+		if !encrypt.IsSealed(*text) {
+			continue
+		}
+		if st.key == nil {
+			return encrypt.ErrLocked
+		}
+
+		opened, err := st.key.OpenString(*text)
+		if err != nil {
+			return err
+		}
+		*text = opened
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package store
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tuanta7/ekko/internal/encrypt"
+)
+
+func TestEncrypt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ekko.db")
+	st := openTest(t, path)
+	s := testSession("20261019-100000-abcdef", at(0))
+	if err := st.Save(s); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := st.Encrypt("hunter2"); err != nil {
+		t.Fatal(err)
+	}
+	if !st.Encrypted() || st.Key() == nil {
+		t.Fatal("the store is not encrypted and unlocked after Encrypt")
+	}
+	got, err := st.Load(s.Manifest.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	equalSessions(t, got, s)
+
+	// the texts are sealed in the database, the timing and the tags are not
+	var title, text, speaker, note, summary string
+	err = st.db.QueryRow(`SELECT s.title, c.text, sp.name, b.note, su.text FROM sessions s
+		JOIN chunks c ON c.session_id = s.id JOIN speakers sp ON sp.id = c.speaker_id
+		JOIN bookmarks b ON b.session_id = s.id JOIN summaries su ON su.session_id = s.id
+		WHERE c.seq = 1`).Scan(&title, &text, &speaker, &note, &summary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{title, text, speaker, note, summary} {
+		if !encrypt.IsSealed(v) {
+			t.Errorf("%q is stored in plaintext", v)
+		}
+	}
+	if err = st.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the plaintext is erased from the database file
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, plain := range []string{"Let us plan the budget", "The team planned the budget", "action item"} {
+		if bytes.Contains(data, []byte(plain)) {
+			t.Errorf("the database file still holds %q", plain)
+		}
+	}
+
+	st = openTest(t, path)
+	if !st.Encrypted() {
+		t.Fatal("the store is not encrypted once reopened")
+	}
+	if _, err = st.Load(s.Manifest.ID); !errors.Is(err, encrypt.ErrLocked) {
+		t.Errorf("Load before Unlock = %v, want ErrLocked", err)
+	}
+	if err = st.Save(s); !errors.Is(err, encrypt.ErrLocked) {
+		t.Errorf("Save before Unlock = %v, want ErrLocked", err)
+	}
+
+	if err = st.Unlock("hunter3"); !errors.Is(err, encrypt.ErrWrongPassphrase) {
+		t.Errorf("Unlock with the wrong passphrase = %v, want ErrWrongPassphrase", err)
+	}
+	if st.Key() != nil {
+		t.Error("the store is unlocked with the wrong passphrase")
+	}
+
+	if err = st.Unlock("hunter2"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err = st.Load(s.Manifest.ID); err != nil {
+		t.Fatal(err)
+	}
+	equalSessions(t, got, s)
+
+	entries, err := st.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Manifest.Title != "Budget" {
+		t.Errorf("List = %+v, want the opened title", entries)
+	}
+
+	// the sessions saved from then on are sealed too
+	s2 := testSession("20261019-110000-bcdefa", at(60))
+	if err = st.Save(s2); err != nil {
+		t.Fatal(err)
+	}
+	if err = st.db.QueryRow(`SELECT title FROM sessions WHERE id = ?`, s2.Manifest.ID).Scan(&title); err != nil {
+		t.Fatal(err)
+	}
+	if !encrypt.IsSealed(title) {
+		t.Errorf("the title of a new session is stored in plaintext: %q", title)
+	}
+}
+
+func TestEncryptEncrypted(t *testing.T) {
+	st := openTest(t, filepath.Join(t.TempDir(), "ekko.db"))
+	if err := st.Encrypt("hunter2"); err != nil {
+		t.Fatal(err)
+	}
+
+	// encrypting again unlocks, with the same passphrase only
+	if err := st.Encrypt("other"); !errors.Is(err, encrypt.ErrWrongPassphrase) {
+		t.Errorf("Encrypt with another passphrase = %v, want ErrWrongPassphrase", err)
+	}
+	if err := st.Encrypt("hunter2"); err != nil {
+		t.Errorf("Encrypt with the same passphrase = %v", err)
+	}
+}
+
+func TestUnlockPlaintext(t *testing.T) {
+	st := openTest(t, filepath.Join(t.TempDir(), "ekko.db"))
+	if err := st.Unlock("hunter2"); err == nil {
+		t.Error("unlocked a store that is not encrypted")
+	}
+}
@@ -0,0 +1,105 @@
+package store
+
+// migrations are applied in order, the number of applied ones is kept in the
+// user_version of the database. Released migrations must never change, add new ones.
+var migrations = []string{
+	`CREATE TABLE sessions (
+		id                TEXT PRIMARY KEY,
+		title             TEXT NOT NULL DEFAULT '',
+		started_at        INTEGER,
+		ended_at          INTEGER,
+		backend           TEXT NOT NULL DEFAULT '',
+		model             TEXT NOT NULL DEFAULT '',
+		language          TEXT NOT NULL DEFAULT '',
+		source            TEXT NOT NULL DEFAULT '',
+		chunk_duration_ms INTEGER NOT NULL DEFAULT 0,
+		app_version       TEXT NOT NULL DEFAULT '',
+		revision          INTEGER NOT NULL DEFAULT 0,
+		parent_id         TEXT NOT NULL DEFAULT '',
+		transcribed_at    INTEGER,
+		audio_file        TEXT,
+		audio_format      TEXT NOT NULL DEFAULT '',
+		updated_at        INTEGER NOT NULL
+	);
+
+	CREATE INDEX sessions_started_at ON sessions (started_at);
+
+	CREATE TABLE speakers (
+		id         INTEGER PRIMARY KEY,
+		session_id TEXT NOT NULL REFERENCES sessions (id) ON DELETE CASCADE,
+		name       TEXT NOT NULL,
+		UNIQUE (session_id, name)
+	);
+
+	CREATE TABLE chunks (
+		session_id TEXT NOT NULL REFERENCES sessions (id) ON DELETE CASCADE,
+		seq        INTEGER NOT NULL,
+		offset_ms  INTEGER NOT NULL,
+		timestamp  INTEGER,
+		text       TEXT NOT NULL DEFAULT '',
+		error      TEXT NOT NULL DEFAULT '',
+		speaker_id INTEGER REFERENCES speakers (id),
+		PRIMARY KEY (session_id, seq)
+	);
+
+	CREATE TABLE segments (
+		session_id  TEXT NOT NULL REFERENCES sessions (id) ON DELETE CASCADE,
+		seq         INTEGER NOT NULL,
+		offset_ms   INTEGER NOT NULL,
+		duration_ms INTEGER NOT NULL,
+		PRIMARY KEY (session_id, seq)
+	);
+
+	CREATE TABLE pauses (
+		session_id TEXT NOT NULL REFERENCES sessions (id) ON DELETE CASCADE,
+		start_ms   INTEGER NOT NULL,
+		end_ms     INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY (session_id, start_ms)
+	);
+
+	CREATE TABLE tags (
+		id   INTEGER PRIMARY KEY,
+		name TEXT NOT NULL UNIQUE
+	);
+
+	CREATE TABLE session_tags (
+		session_id TEXT NOT NULL REFERENCES sessions (id) ON DELETE CASCADE,
+		tag_id     INTEGER NOT NULL REFERENCES tags (id),
+		position   INTEGER NOT NULL,
+		PRIMARY KEY (session_id, tag_id)
+	);`,
+
+	`CREATE TABLE bookmarks (
+		session_id TEXT NOT NULL REFERENCES sessions (id) ON DELETE CASCADE,
+		position   INTEGER NOT NULL,
+		offset_ms  INTEGER NOT NULL,
+		timestamp  INTEGER,
+		note       TEXT NOT NULL DEFAULT '',
+		PRIMARY KEY (session_id, position)
+	);`,
+
+	`CREATE TABLE summaries (
+		session_id TEXT PRIMARY KEY REFERENCES sessions (id) ON DELETE CASCADE,
+		text       TEXT NOT NULL,
+		model      TEXT NOT NULL DEFAULT '',
+		created_at INTEGER
+	);
+
+	CREATE TABLE summary_items (
+		session_id TEXT NOT NULL REFERENCES sessions (id) ON DELETE CASCADE,
+		kind       TEXT NOT NULL, -- decision or action
+		position   INTEGER NOT NULL,
+		text       TEXT NOT NULL,
+		owner      TEXT NOT NULL DEFAULT '',
+		PRIMARY KEY (session_id, kind, position)
+	);`,
+
+	`ALTER TABLE chunks ADD COLUMN raw_text TEXT NOT NULL DEFAULT '';
+	ALTER TABLE chunks ADD COLUMN paragraph INTEGER NOT NULL DEFAULT 0;`,
+
+	`CREATE TABLE encryption (
+		id       INTEGER PRIMARY KEY CHECK (id = 1),
+		salt     BLOB NOT NULL,
+		verifier TEXT NOT NULL -- a known value sealed with the key, to check passphrases
+	);`,
+}
@@ -0,0 +1,561 @@
+// Package store keeps the saved sessions in a SQLite database.
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/tuanta7/ekko/internal/encrypt"
+	"github.com/tuanta7/ekko/internal/session"
+	"github.com/tuanta7/ekko/pkg/xdg"
+	_ "modernc.org/sqlite"
+)
+
+// DefaultPath returns where the database is kept when none is configured,
+// $XDG_DATA_HOME/ekko/ekko.db.
+func DefaultPath() string {
+	return filepath.Join(xdg.DataHome(), "ekko", "ekko.db")
+}
+
+// SQLite stores sessions, their chunks, audio segments, pauses, bookmarks, summaries,
+// speakers and tags in relational tables. It is safe for concurrent use, also by several processes,
+// e.g. the TUI and ekko serve.
+//
+// Once encrypted, the texts of the sessions, their titles, chunks, speakers, bookmark
+// notes and summaries, are sealed with a key derived from a passphrase, see Encrypt.
+// Their timing and tags are not.
+type SQLite struct {
+	db *sql.DB
+	// encrypted is set when the sessions are encrypted, key once they are unlocked
+	encrypted bool
+	key       *encrypt.Key
+}
+
+// Open opens the database at path, creating it and bringing its schema up to date.
+func Open(path string) (*SQLite, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create database directory: %w", err)
+	}
+	if err := restrictMode(path); err != nil {
+		return nil, fmt.Errorf("failed to restrict the database permissions: %w", err)
+	}
+
+	// the pragmas are set on every connection of the pool, secure_delete overwrites
+	// the deleted transcripts instead of leaving them in the free pages
+	dsn := "file:" + path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)" +
+		"&_pragma=secure_delete(1)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = migrate(db); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to migrate %s: %w", path, err)
+	}
+
+	st := &SQLite{db: db}
+	if err = db.QueryRow(`SELECT EXISTS (SELECT 1 FROM encryption)`).Scan(&st.encrypted); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return st, nil
+}
+
+// restrictMode makes the database readable by its owner only. An empty file is a valid
+// empty database, SQLite creates the WAL files with the mode of the database.
+func restrictMode(path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+
+	for _, p := range []string{path, path + "-wal", path + "-shm"} {
+		if err = os.Chmod(p, 0600); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+func migrate(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		return err
+	}
+
+	for i := version; i < len(migrations); i++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+
+		if _, err = tx.Exec(migrations[i]); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("migration %d: %w", i+1, err)
+		}
+		// PRAGMA does not take parameters
+		if _, err = tx.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, i+1)); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+
+		if err = tx.Commit(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (st *SQLite) Close() error {
+	return st.db.Close()
+}
+
+// Save inserts the session, or replaces the saved session with the same ID. Saving to
+// an encrypted store that is not unlocked fails with encrypt.ErrLocked.
+func (st *SQLite) Save(s *session.Session) error {
+	if st.encrypted && st.key == nil {
+		return encrypt.ErrLocked
+	}
+
+	return st.inTx(func(tx *sql.Tx) error {
+		return st.save(tx, s)
+	})
+}
+
+func (st *SQLite) save(tx *sql.Tx, s *session.Session) error {
+	if _, err := tx.Exec(`DELETE FROM sessions WHERE id = ?`, s.Manifest.ID); err != nil {
+		return err
+	}
+
+	m := s.Manifest
+	var audioFile sql.NullString
+	var audioFormat string
+	if m.Audio != nil {
+		audioFile = sql.NullString{String: m.Audio.File, Valid: true}
+		audioFormat = m.Audio.Format
+	}
+
+	_, err := tx.Exec(`INSERT INTO sessions (id, title, started_at, ended_at, backend, model, language,
+			source, chunk_duration_ms, app_version, revision, parent_id, transcribed_at, audio_file,
+			audio_format, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		m.ID, st.seal(m.Title), toMS(m.StartedAt), toMS(m.EndedAt), m.Backend, m.Model, m.Language,
+		m.Source, m.ChunkDurationMS, m.AppVersion, m.Revision, m.ParentID, toMS(m.TranscribedAt),
+		audioFile, audioFormat, time.Now().UnixMilli())
+	if err != nil {
+		return err
+	}
+
+	for i, tag := range m.Tags {
+		if _, err = tx.Exec(`INSERT INTO tags (name) VALUES (?) ON CONFLICT (name) DO NOTHING`, tag); err != nil {
+			return err
+		}
+		_, err = tx.Exec(`INSERT INTO session_tags (session_id, tag_id, position)
+				SELECT ?, id, ? FROM tags WHERE name = ? ON CONFLICT DO NOTHING`, m.ID, i, tag)
+		if err != nil {
+			return err
+		}
+	}
+
+	speakers := make(map[string]int64)
+	for _, c := range s.Chunks {
+		var speaker sql.NullInt64
+		if c.Speaker != "" {
+			id, ok := speakers[c.Speaker]
+			if !ok {
+				res, err := tx.Exec(`INSERT INTO speakers (session_id, name) VALUES (?, ?)`, m.ID, st.seal(c.Speaker))
+				if err != nil {
+					return err
+				}
+				if id, err = res.LastInsertId(); err != nil {
+					return err
+				}
+				speakers[c.Speaker] = id
+			}
+			speaker = sql.NullInt64{Int64: id, Valid: true}
+		}
+
+		_, err = tx.Exec(`INSERT INTO chunks (session_id, seq, offset_ms, timestamp, text, error, speaker_id, raw_text, paragraph)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			m.ID, c.Seq, c.OffsetMS, toMS(c.Timestamp), st.seal(c.Text), c.Error, speaker, st.seal(c.RawText), c.Paragraph)
+		if err != nil {
+			return fmt.Errorf("chunk %d: %w", c.Seq, err)
+		}
+	}
+
+	if m.Audio != nil {
+		for _, seg := range m.Audio.Segments {
+			_, err = tx.Exec(`INSERT INTO segments (session_id, seq, offset_ms, duration_ms) VALUES (?, ?, ?, ?)`,
+				m.ID, seg.Seq, seg.OffsetMS, seg.DurationMS)
+			if err != nil {
+				return fmt.Errorf("audio segment %d: %w", seg.Seq, err)
+			}
+		}
+	}
+
+	for _, p := range s.Pauses {
+		_, err = tx.Exec(`INSERT INTO pauses (session_id, start_ms, end_ms) VALUES (?, ?, ?)`,
+			m.ID, p.StartMS, p.EndMS)
+		if err != nil {
+			return err
+		}
+	}
+
+	for i, b := range s.Bookmarks {
+		_, err = tx.Exec(`INSERT INTO bookmarks (session_id, position, offset_ms, timestamp, note) VALUES (?, ?, ?, ?, ?)`,
+			m.ID, i, b.OffsetMS, toMS(b.Timestamp), st.seal(b.Note))
+		if err != nil {
+			return err
+		}
+	}
+
+	if sum := s.Summary; sum != nil {
+		if err = st.insertSummary(tx, m.ID, sum); err != nil {
+			return fmt.Errorf("summary: %w", err)
+		}
+	}
+
+	return deleteUnusedTags(tx)
+}
+
+// Load returns the session with the given ID, or session.ErrNotFound.
+func (st *SQLite) Load(id string) (*session.Session, error) {
+	entry, err := scanEntry(st.db.QueryRow(selectEntry+` WHERE s.id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", session.ErrNotFound, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = st.open(&entry.Manifest.Title); err != nil {
+		return nil, err
+	}
+
+	s := session.New()
+	s.Manifest = entry.Manifest
+	if s.Manifest.Tags, err = st.tags(id); err != nil {
+		return nil, err
+	}
+
+	rows, err := st.db.Query(`SELECT c.seq, c.offset_ms, c.timestamp, c.text, c.error, COALESCE(sp.name, ''),
+		c.raw_text, c.paragraph FROM chunks c LEFT JOIN speakers sp ON sp.id = c.speaker_id
+		WHERE c.session_id = ? ORDER BY c.seq`, id)
+	if err != nil {
+		return nil, err
+	}
+	err = eachRow(rows, func() error {
+		var c session.Chunk
+		var timestamp sql.NullInt64
+		if err := rows.Scan(&c.Seq, &c.OffsetMS, &timestamp, &c.Text, &c.Error, &c.Speaker, &c.RawText, &c.Paragraph); err != nil {
+			return err
+		}
+		if err := st.open(&c.Text, &c.Speaker, &c.RawText); err != nil {
+			return err
+		}
+		c.Timestamp = fromMS(timestamp)
+		s.Chunks = append(s.Chunks, c)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if s.Manifest.Audio != nil {
+		rows, err = st.db.Query(`SELECT seq, offset_ms, duration_ms FROM segments WHERE session_id = ? ORDER BY seq`, id)
+		if err != nil {
+			return nil, err
+		}
+		err = eachRow(rows, func() error {
+			var seg session.AudioSegment
+			if err := rows.Scan(&seg.Seq, &seg.OffsetMS, &seg.DurationMS); err != nil {
+				return err
+			}
+			s.Manifest.Audio.Segments = append(s.Manifest.Audio.Segments, seg)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err = st.db.Query(`SELECT start_ms, end_ms FROM pauses WHERE session_id = ? ORDER BY start_ms`, id)
+	if err != nil {
+		return nil, err
+	}
+	err = eachRow(rows, func() error {
+		var p session.Pause
+		if err := rows.Scan(&p.StartMS, &p.EndMS); err != nil {
+			return err
+		}
+		s.Pauses = append(s.Pauses, p)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = st.db.Query(`SELECT offset_ms, timestamp, note FROM bookmarks WHERE session_id = ? ORDER BY position`, id)
+	if err != nil {
+		return nil, err
+	}
+	err = eachRow(rows, func() error {
+		var b session.Bookmark
+		var timestamp sql.NullInt64
+		if err := rows.Scan(&b.OffsetMS, &timestamp, &b.Note); err != nil {
+			return err
+		}
+		if err := st.open(&b.Note); err != nil {
+			return err
+		}
+		b.Timestamp = fromMS(timestamp)
+		s.Bookmarks = append(s.Bookmarks, b)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if s.Summary, err = st.summary(id); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+const (
+	itemDecision = "decision"
+	itemAction   = "action"
+)
+
+func (st *SQLite) insertSummary(tx *sql.Tx, id string, sum *session.Summary) error {
+	_, err := tx.Exec(`INSERT INTO summaries (session_id, text, model, created_at) VALUES (?, ?, ?, ?)`,
+		id, st.seal(sum.Text), sum.Model, toMS(sum.CreatedAt))
+	if err != nil {
+		return err
+	}
+
+	const insertItem = `INSERT INTO summary_items (session_id, kind, position, text, owner) VALUES (?, ?, ?, ?, ?)`
+	for i, d := range sum.Decisions {
+		if _, err = tx.Exec(insertItem, id, itemDecision, i, st.seal(d), ""); err != nil {
+			return err
+		}
+	}
+	for i, item := range sum.ActionItems {
+		if _, err = tx.Exec(insertItem, id, itemAction, i, st.seal(item.Task), st.seal(item.Owner)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// summary returns the summary of a session, nil when it has none.
+func (st *SQLite) summary(id string) (*session.Summary, error) {
+	var sum session.Summary
+	var createdAt sql.NullInt64
+	err := st.db.QueryRow(`SELECT text, model, created_at FROM summaries WHERE session_id = ?`, id).
+		Scan(&sum.Text, &sum.Model, &createdAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = st.open(&sum.Text); err != nil {
+		return nil, err
+	}
+	sum.CreatedAt = fromMS(createdAt)
+
+	rows, err := st.db.Query(`SELECT kind, text, owner FROM summary_items WHERE session_id = ? ORDER BY kind, position`, id)
+	if err != nil {
+		return nil, err
+	}
+	err = eachRow(rows, func() error {
+		var kind, text, owner string
+		if err := rows.Scan(&kind, &text, &owner); err != nil {
+			return err
+		}
+		if err := st.open(&text, &owner); err != nil {
+			return err
+		}
+		switch kind {
+		case itemDecision:
+			sum.Decisions = append(sum.Decisions, text)
+		case itemAction:
+			sum.ActionItems = append(sum.ActionItems, session.ActionItem{Task: text, Owner: owner})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &sum, nil
+}
+
+// List returns the saved sessions without their transcript, most recent first.
+func (st *SQLite) List() ([]session.Entry, error) {
+	tags := make(map[string][]string)
+	rows, err := st.db.Query(`SELECT st.session_id, t.name FROM session_tags st JOIN tags t ON t.id = st.tag_id
+		ORDER BY st.session_id, st.position`)
+	if err != nil {
+		return nil, err
+	}
+	err = eachRow(rows, func() error {
+		var id, name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return err
+		}
+		tags[id] = append(tags[id], name)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []session.Entry
+	rows, err = st.db.Query(selectEntry + ` ORDER BY s.started_at DESC, s.id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	err = eachRow(rows, func() error {
+		e, err := scanEntry(rows)
+		if err != nil {
+			return err
+		}
+		if err = st.open(&e.Manifest.Title); err != nil {
+			return err
+		}
+		e.Manifest.Tags = tags[e.Manifest.ID]
+		entries = append(entries, e)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// Delete deletes the session with the given ID, or returns session.ErrNotFound.
+func (st *SQLite) Delete(id string) error {
+	return st.inTx(func(tx *sql.Tx) error {
+		res, err := tx.Exec(`DELETE FROM sessions WHERE id = ?`, id)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err == nil && n == 0 {
+			return fmt.Errorf("%w: %s", session.ErrNotFound, id)
+		}
+		return deleteUnusedTags(tx)
+	})
+}
+
+func (st *SQLite) tags(id string) ([]string, error) {
+	rows, err := st.db.Query(`SELECT t.name FROM session_tags st JOIN tags t ON t.id = st.tag_id
+		WHERE st.session_id = ? ORDER BY st.position`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []string
+	err = eachRow(rows, func() error {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		tags = append(tags, name)
+		return nil
+	})
+	return tags, err
+}
+
+func (st *SQLite) inTx(fn func(tx *sql.Tx) error) error {
+	tx, err := st.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+
+	if err = fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func deleteUnusedTags(tx *sql.Tx) error {
+	_, err := tx.Exec(`DELETE FROM tags WHERE id NOT IN (SELECT tag_id FROM session_tags)`)
+	return err
+}
+
+const selectEntry = `SELECT s.id, s.title, s.started_at, s.ended_at, s.backend, s.model, s.language, s.source,
+	s.chunk_duration_ms, s.app_version, s.revision, s.parent_id, s.transcribed_at, s.audio_file,
+	s.audio_format, s.updated_at, (SELECT COUNT(*) FROM chunks c WHERE c.session_id = s.id)
+	FROM sessions s`
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanEntry(row scanner) (session.Entry, error) {
+	var e session.Entry
+	var startedAt, endedAt, transcribedAt sql.NullInt64
+	var audioFile sql.NullString
+	var audioFormat string
+	var updatedAt int64
+
+	m := &e.Manifest
+	err := row.Scan(&m.ID, &m.Title, &startedAt, &endedAt, &m.Backend, &m.Model, &m.Language, &m.Source,
+		&m.ChunkDurationMS, &m.AppVersion, &m.Revision, &m.ParentID, &transcribedAt, &audioFile,
+		&audioFormat, &updatedAt, &e.Chunks)
+	if err != nil {
+		return e, err
+	}
+
+	m.StartedAt = fromMS(startedAt)
+	m.EndedAt = fromMS(endedAt)
+	m.TranscribedAt = fromMS(transcribedAt)
+	if audioFile.Valid {
+		m.Audio = &session.Audio{File: audioFile.String, Format: audioFormat}
+	}
+	e.UpdatedAt = time.UnixMilli(updatedAt)
+
+	return e, nil
+}
+
+// eachRow calls fn for every row and closes the rows.
+func eachRow(rows *sql.Rows, fn func() error) error {
+	defer rows.Close()
+	for rows.Next() {
+		if err := fn(); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// toMS stores times as unix milliseconds, so they sort, and the zero time as NULL.
+func toMS(t time.Time) sql.NullInt64 {
+	if t.IsZero() {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: t.UnixMilli(), Valid: true}
+}
+
+func fromMS(ms sql.NullInt64) time.Time {
+	if !ms.Valid {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms.Int64)
+}
@@ -0,0 +1,246 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/tuanta7/ekko/internal/session"
+)
+
+func openTest(t *testing.T, path string) *SQLite {
+	t.Helper()
+	st, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = st.Close() })
+	return st
+}
+
+// at returns a time as the store reads it back, to the millisecond.
+func at(sec int) time.Time {
+	return time.UnixMilli(time.Date(2026, 10, 19, 10, 0, sec, 0, time.UTC).UnixMilli())
+}
+
+func testSession(id string, started time.Time) *session.Session {
+	s := session.New()
+	s.Manifest = session.Manifest{
+		ID:              id,
+		Title:           "Budget",
+		Tags:            []string{"finance", "q4"},
+		StartedAt:       started,
+		EndedAt:         started.Add(95 * time.Second),
+		Backend:         "whisper",
+		Model:           "base",
+		Language:        "en",
+		Source:          "default",
+		ChunkDurationMS: 10_000,
+		AppVersion:      "dev",
+		Revision:        1,
+		Audio: &session.Audio{File: "audio/" + id + ".opus", Format: "opus", Segments: []session.AudioSegment{
+			{Seq: 1, OffsetMS: 0, DurationMS: 10_000},
+			{Seq: 2, OffsetMS: 10_000, DurationMS: 10_000},
+		}},
+	}
+	s.Chunks = []session.Chunk{
+		{Seq: 1, OffsetMS: 0, Timestamp: started, Text: "Let us plan the budget.", Speaker: "Alex", RawText: "let us plan the budget", Paragraph: true},
+		{Seq: 2, OffsetMS: 10_000, Timestamp: started.Add(10 * time.Second), Text: "I send it.", Speaker: "Sam"},
+		{Seq: 3, OffsetMS: 20_000, Timestamp: started.Add(20 * time.Second), Text: "Before Friday.", Speaker: "Alex"},
+		{Seq: 4, OffsetMS: 30_000, Timestamp: started.Add(30 * time.Second), Error: "timeout"},
+	}
+	s.Pauses = []session.Pause{{StartMS: 40_000, EndMS: 65_000}}
+	s.Bookmarks = []session.Bookmark{{OffsetMS: 25_000, Timestamp: started.Add(25 * time.Second), Note: "action item"}}
+	s.Summary = &session.Summary{
+		Text:        "The team planned the budget.",
+		Decisions:   []string{"Ship on Friday", "Keep the scope"},
+		ActionItems: []session.ActionItem{{Task: "Send the budget", Owner: "Sam"}, {Task: "Book a room"}},
+		Model:       "openai/test",
+		CreatedAt:   started.Add(time.Hour),
+	}
+	return s
+}
+
+func equalSessions(t *testing.T, got, want *session.Session) {
+	t.Helper()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("loaded session:\n%+v\nwant:\n%+v", got, want)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	st := openTest(t, filepath.Join(t.TempDir(), "ekko.db"))
+	want := testSession("20261019-100000-abcdef", at(0))
+
+	if err := st.Save(want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := st.Load(want.Manifest.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	equalSessions(t, got, want)
+
+	// saving again replaces the session
+	want.Manifest.Title = "Budget, revised"
+	want.Manifest.Tags = []string{"q4"}
+	want.Chunks = want.Chunks[:2]
+	want.Summary = nil
+	want.Manifest.Audio = nil
+	if err = st.Save(want); err != nil {
+		t.Fatal(err)
+	}
+	if got, err = st.Load(want.Manifest.ID); err != nil {
+		t.Fatal(err)
+	}
+	equalSessions(t, got, want)
+
+	if _, err = st.Load("missing"); !errors.Is(err, session.ErrNotFound) {
+		t.Errorf("Load of a missing session = %v, want ErrNotFound", err)
+	}
+}
+
+func TestSaveLoadMinimal(t *testing.T) {
+	st := openTest(t, filepath.Join(t.TempDir(), "ekko.db"))
+	want := session.New()
+
+	if err := st.Save(want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := st.Load(want.Manifest.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	equalSessions(t, got, want)
+}
+
+func TestListDelete(t *testing.T) {
+	st := openTest(t, filepath.Join(t.TempDir(), "ekko.db"))
+	older := testSession("20261018-100000-aaaaaa", at(0).Add(-24*time.Hour))
+	newer := testSession("20261019-100000-bbbbbb", at(0))
+	newer.Manifest.Tags = []string{"standup"}
+	for _, s := range []*session.Session{older, newer} {
+		if err := st.Save(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := st.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Manifest.ID != newer.Manifest.ID || entries[1].Manifest.ID != older.Manifest.ID {
+		t.Fatalf("List = %+v, want the newer session first", entries)
+	}
+	e := entries[1]
+	if e.Chunks != 4 || e.Manifest.Title != "Budget" || !reflect.DeepEqual(e.Manifest.Tags, []string{"finance", "q4"}) {
+		t.Errorf("entry = %+v", e)
+	}
+	if e.Manifest.Audio == nil || e.Manifest.Audio.File != older.Manifest.Audio.File {
+		t.Errorf("entry audio = %+v, want the recording", e.Manifest.Audio)
+	}
+	if e.UpdatedAt.IsZero() {
+		t.Error("entry without update time")
+	}
+
+	if err = st.Delete(older.Manifest.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err = st.Delete(older.Manifest.ID); !errors.Is(err, session.ErrNotFound) {
+		t.Errorf("second Delete = %v, want ErrNotFound", err)
+	}
+	if entries, err = st.List(); err != nil || len(entries) != 1 {
+		t.Errorf("List after Delete = %d sessions, %v", len(entries), err)
+	}
+
+	// the tags of the deleted session are gone with it
+	var tags int
+	if err = st.db.QueryRow(`SELECT COUNT(*) FROM tags`).Scan(&tags); err != nil {
+		t.Fatal(err)
+	}
+	if tags != 1 {
+		t.Errorf("%d tags left, want the one of the remaining session", tags)
+	}
+}
+
+func userVersion(t *testing.T, db *sql.DB) int {
+	t.Helper()
+	var version int
+	if err := db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		t.Fatal(err)
+	}
+	return version
+}
+
+func TestMigrate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ekko.db")
+
+	st := openTest(t, path)
+	if v := userVersion(t, st.db); v != len(migrations) {
+		t.Errorf("user_version = %d, want %d", v, len(migrations))
+	}
+	if st.Encrypted() {
+		t.Error("a new store is encrypted")
+	}
+	s := testSession("20261019-100000-abcdef", at(0))
+	if err := st.Save(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// opening again applies nothing and keeps the sessions
+	st = openTest(t, path)
+	if v := userVersion(t, st.db); v != len(migrations) {
+		t.Errorf("user_version = %d after reopening, want %d", v, len(migrations))
+	}
+	got, err := st.Load(s.Manifest.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	equalSessions(t, got, s)
+}
+
+func TestMigrateFromFirstVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ekko.db")
+	db, err := sql.Open("sqlite", "file:"+path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = db.Exec(migrations[0] + `PRAGMA user_version = 1;`); err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(`INSERT INTO sessions (id, title, started_at, updated_at) VALUES ('old', 'Before bookmarks', ?, 0);
+		INSERT INTO chunks (session_id, seq, offset_ms, text) VALUES ('old', 1, 0, 'let us plan');`, at(0).UnixMilli())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	st := openTest(t, path)
+	if v := userVersion(t, st.db); v != len(migrations) {
+		t.Errorf("user_version = %d, want %d", v, len(migrations))
+	}
+
+	s, err := st.Load("old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Manifest.Title != "Before bookmarks" || len(s.Chunks) != 1 || s.Chunks[0].Text != "let us plan" {
+		t.Errorf("migrated session = %+v", s)
+	}
+	if s.Chunks[0].RawText != "" || s.Chunks[0].Paragraph {
+		t.Errorf("migrated chunk = %+v, want the defaults of the new columns", s.Chunks[0])
+	}
+
+	// the tables of the later migrations are usable
+	if err = st.Save(testSession("new", at(0))); err != nil {
+		t.Fatal(err)
+	}
+}
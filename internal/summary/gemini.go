@@ -0,0 +1,88 @@
+package summary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/tuanta7/ekko/internal/session"
+	"github.com/tuanta7/ekko/internal/transcriber"
+	"google.golang.org/genai"
+)
+
+type Gemini struct {
+	client *genai.Client
+	model  string
+}
+
+func NewGemini(ctx context.Context, opts Options) (*Gemini, error) {
+	if opts.APIKey == "" {
+		return nil, errors.New("the gemini summary provider needs an API key")
+	}
+	if opts.Model == "" {
+		opts.Model = transcriber.DefaultGeminiModel
+	}
+
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+		APIKey:      opts.APIKey,
+		Backend:     genai.BackendGeminiAPI,
+		HTTPOptions: genai.HTTPOptions{BaseURL: opts.BaseURL},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Gemini{client: client, model: opts.Model}, nil
+}
+
+func (g *Gemini) Summarize(ctx context.Context, s *session.Session) (*session.Summary, error) {
+	text, err := prompt(s)
+	if err != nil {
+		return nil, err
+	}
+
+	temperature := float32(0.2)
+	resp, err := g.client.Models.GenerateContent(ctx, g.model, genai.Text(text), &genai.GenerateContentConfig{
+		SystemInstruction: genai.NewContentFromText(instructions, genai.RoleUser),
+		Temperature:       &temperature,
+		ResponseMIMEType:  "application/json",
+		ResponseSchema:    replySchema,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("gemini: %w", err)
+	}
+
+	return parse(resp.Text(), string(GeminiProvider)+"/"+g.model)
+}
+
+func (g *Gemini) Punctuate(ctx context.Context, prev, text string) (string, error) {
+	temperature := float32(0.2)
+	resp, err := g.client.Models.GenerateContent(ctx, g.model, genai.Text(punctuatePrompt(prev, text)), &genai.GenerateContentConfig{
+		SystemInstruction: genai.NewContentFromText(punctuateInstructions, genai.RoleUser),
+		Temperature:       &temperature,
+	})
+	if err != nil {
+		return "", fmt.Errorf("gemini: %w", err)
+	}
+	return resp.Text(), nil
+}
+
+var replySchema = &genai.Schema{
+	Type: genai.TypeObject,
+	Properties: map[string]*genai.Schema{
+		"summary":   {Type: genai.TypeString},
+		"decisions": {Type: genai.TypeArray, Items: &genai.Schema{Type: genai.TypeString}},
+		"action_items": {
+			Type: genai.TypeArray,
+			Items: &genai.Schema{
+				Type: genai.TypeObject,
+				Properties: map[string]*genai.Schema{
+					"task":  {Type: genai.TypeString},
+					"owner": {Type: genai.TypeString},
+				},
+				Required: []string{"task"},
+			},
+		},
+	},
+	Required: []string{"summary", "decisions", "action_items"},
+}
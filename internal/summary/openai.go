@@ -0,0 +1,142 @@
+package summary
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/tuanta7/ekko/internal/session"
+)
+
+const (
+	DefaultOpenAIBaseURL = "https://api.openai.com/v1"
+	DefaultOpenAIModel   = "gpt-4o-mini"
+)
+
+// OpenAI uses the chat completions API of OpenAI or of a compatible server. The API
+// key is optional, local servers seldom need one.
+type OpenAI struct {
+	http    *http.Client
+	baseURL string
+	apiKey  string
+	model   string
+}
+
+func NewOpenAI(opts Options) *OpenAI {
+	if opts.BaseURL == "" {
+		opts.BaseURL = DefaultOpenAIBaseURL
+	}
+	if opts.Model == "" {
+		opts.Model = DefaultOpenAIModel
+	}
+
+	return &OpenAI{
+		http:    http.DefaultClient,
+		baseURL: strings.TrimSuffix(opts.BaseURL, "/"),
+		apiKey:  opts.APIKey,
+		model:   opts.Model,
+	}
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model          string          `json:"model"`
+	Messages       []chatMessage   `json:"messages"`
+	Temperature    float32         `json:"temperature"`
+	ResponseFormat *responseFormat `json:"response_format,omitempty"`
+}
+
+type responseFormat struct {
+	Type string `json:"type"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (o *OpenAI) Summarize(ctx context.Context, s *session.Session) (*session.Summary, error) {
+	text, err := prompt(s)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := o.complete(ctx, instructions, text, true)
+	if err != nil {
+		return nil, err
+	}
+	return parse(reply, string(OpenAIProvider)+"/"+o.model)
+}
+
+func (o *OpenAI) Punctuate(ctx context.Context, prev, text string) (string, error) {
+	return o.complete(ctx, punctuateInstructions, punctuatePrompt(prev, text), false)
+}
+
+// complete sends the instructions and the text to the model and returns its reply,
+// asking for a JSON object when jsonReply is set.
+func (o *OpenAI) complete(ctx context.Context, system, text string, jsonReply bool) (string, error) {
+	req := chatRequest{
+		Model: o.model,
+		Messages: []chatMessage{
+			{Role: "system", Content: system},
+			{Role: "user", Content: text},
+		},
+		Temperature: 0.2,
+	}
+	if jsonReply {
+		req.ResponseFormat = &responseFormat{Type: "json_object"}
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, o.baseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if o.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+o.apiKey)
+	}
+
+	resp, err := o.http.Do(httpReq)
+	if err != nil {
+		return "", fmt.Errorf("openai: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return "", fmt.Errorf("openai: %w", err)
+	}
+
+	var chat chatResponse
+	if err = json.Unmarshal(data, &chat); err != nil && resp.StatusCode == http.StatusOK {
+		return "", fmt.Errorf("openai: invalid response: %w", err)
+	}
+
+	switch {
+	case chat.Error != nil:
+		return "", fmt.Errorf("openai: %s (%s)", chat.Error.Message, resp.Status)
+	case resp.StatusCode != http.StatusOK:
+		return "", fmt.Errorf("openai: %s", resp.Status)
+	case len(chat.Choices) == 0:
+		return "", fmt.Errorf("openai: no choices in the response")
+	}
+
+	return chat.Choices[0].Message.Content, nil
+}
@@ -0,0 +1,198 @@
+package summary
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tuanta7/ekko/internal/session"
+)
+
+func testSession() *session.Session {
+	s := session.New()
+	s.Manifest.Title = "Budget"
+	s.Manifest.StartedAt = time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	s.Append(session.Chunk{Seq: 1, OffsetMS: 0, Text: "let us plan the budget"})
+	s.Append(session.Chunk{Seq: 2, OffsetMS: 10_000, Text: "Sam sends it friday", Speaker: "Alex"})
+	s.AddBookmark(session.Bookmark{OffsetMS: 12_000, Note: "action item"})
+	return s
+}
+
+// fakeOpenAI answers the chat completions with status and body, and records the
+// request it received.
+func fakeOpenAI(t *testing.T, status int, body string) (*OpenAI, *http.Request, *chatRequest) {
+	t.Helper()
+	var got http.Request
+	var chat chatRequest
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = *r.Clone(context.Background())
+		if err := json.NewDecoder(r.Body).Decode(&chat); err != nil {
+			t.Errorf("invalid request body: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+
+	return NewOpenAI(Options{BaseURL: srv.URL + "/v1/", APIKey: "sk-test", Model: "test-model"}), &got, &chat
+}
+
+func completion(content string) string {
+	data, _ := json.Marshal(map[string]any{
+		"choices": []any{map[string]any{"message": map[string]string{"role": "assistant", "content": content}}},
+	})
+	return string(data)
+}
+
+const summaryReply = `{"summary": " The team planned the budget. ", "decisions": ["Ship on Friday", " "],
+"action_items": [{"task": "Send the budget", "owner": "Sam"}, {"task": "Book a room", "owner": ""}, {"task": ""}]}`
+
+func TestOpenAISummarize(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"plain reply", summaryReply},
+		{"json code block", "```json\n" + summaryReply + "\n```"},
+		{"code block", "```\n" + summaryReply + "\n```"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, req, chat := fakeOpenAI(t, http.StatusOK, completion(tt.content))
+
+			sum, err := client.Summarize(context.Background(), testSession())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if req.Method != http.MethodPost || req.URL.Path != "/v1/chat/completions" {
+				t.Errorf("request = %s %s, want POST /v1/chat/completions", req.Method, req.URL.Path)
+			}
+			if got := req.Header.Get("Authorization"); got != "Bearer sk-test" {
+				t.Errorf("Authorization = %q, want Bearer sk-test", got)
+			}
+			if chat.Model != "test-model" {
+				t.Errorf("model = %q, want test-model", chat.Model)
+			}
+			if chat.ResponseFormat == nil || chat.ResponseFormat.Type != "json_object" {
+				t.Errorf("response_format = %v, want json_object", chat.ResponseFormat)
+			}
+			if len(chat.Messages) != 2 || chat.Messages[0].Role != "system" || chat.Messages[1].Role != "user" {
+				t.Fatalf("messages = %+v, want a system and a user message", chat.Messages)
+			}
+			for _, want := range []string{
+				"Title: Budget",
+				"Date: 2026-10-19",
+				"[00:00:00] let us plan the budget",
+				"[00:00:10] Alex: Sam sends it friday",
+				"[00:00:12] (bookmark) action item",
+			} {
+				if !strings.Contains(chat.Messages[1].Content, want) {
+					t.Errorf("prompt lacks %q:\n%s", want, chat.Messages[1].Content)
+				}
+			}
+
+			if sum.Text != "The team planned the budget." {
+				t.Errorf("summary = %q", sum.Text)
+			}
+			if sum.Model != "openai/test-model" {
+				t.Errorf("model = %q, want openai/test-model", sum.Model)
+			}
+			if len(sum.Decisions) != 1 || sum.Decisions[0] != "Ship on Friday" {
+				t.Errorf("decisions = %q", sum.Decisions)
+			}
+			wantItems := []session.ActionItem{{Task: "Send the budget", Owner: "Sam"}, {Task: "Book a room"}}
+			if len(sum.ActionItems) != len(wantItems) {
+				t.Fatalf("action items = %+v, want %+v", sum.ActionItems, wantItems)
+			}
+			for i, item := range wantItems {
+				if sum.ActionItems[i] != item {
+					t.Errorf("action item %d = %+v, want %+v", i, sum.ActionItems[i], item)
+				}
+			}
+		})
+	}
+}
+
+func TestOpenAIErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   string
+	}{
+		{"error object", http.StatusUnauthorized, `{"error": {"message": "invalid api key"}}`, "invalid api key (401 Unauthorized)"},
+		{"error object with 200", http.StatusOK, `{"error": {"message": "model overloaded"}}`, "model overloaded"},
+		{"non-200 without error", http.StatusBadGateway, `<html>bad gateway</html>`, "502 Bad Gateway"},
+		{"no choices", http.StatusOK, `{"choices": []}`, "no choices"},
+		{"invalid response", http.StatusOK, `not json`, "invalid response"},
+		{"invalid reply", http.StatusOK, completion("I cannot summarize this."), "invalid reply"},
+		{"no summary", http.StatusOK, completion(`{"summary": "", "decisions": []}`), "no summary"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _, _ := fakeOpenAI(t, tt.status, tt.body)
+
+			_, err := client.Summarize(context.Background(), testSession())
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenAIEmptyTranscript(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the model was called for an empty transcript")
+	}))
+	defer srv.Close()
+	client := NewOpenAI(Options{BaseURL: srv.URL})
+
+	s := session.New()
+	s.Append(session.Chunk{Seq: 1, Text: "  "})
+	s.Append(session.Chunk{Seq: 2, Error: "failed to transcribe"})
+	s.AddBookmark(session.Bookmark{OffsetMS: 1000, Note: "nothing said"})
+
+	if _, err := client.Summarize(context.Background(), s); !errors.Is(err, ErrEmptyTranscript) {
+		t.Errorf("error = %v, want ErrEmptyTranscript", err)
+	}
+}
+
+func TestOpenAIPunctuate(t *testing.T) {
+	client, _, chat := fakeOpenAI(t, http.StatusOK, completion("Let us plan the budget."))
+
+	got, err := client.Punctuate(context.Background(), "Hello everyone.", "let us plan the budget")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "Let us plan the budget." {
+		t.Errorf("Punctuate = %q", got)
+	}
+	if chat.ResponseFormat != nil {
+		t.Errorf("response_format = %v, want none", chat.ResponseFormat)
+	}
+	if !strings.Contains(chat.Messages[1].Content, "let us plan the budget") {
+		t.Errorf("prompt lacks the chunk: %q", chat.Messages[1].Content)
+	}
+}
+
+func TestOpenAIWithoutAPIKey(t *testing.T) {
+	client, req, _ := fakeOpenAI(t, http.StatusOK, completion(summaryReply))
+	client.apiKey = ""
+
+	if _, err := client.Summarize(context.Background(), testSession()); err != nil {
+		t.Fatal(err)
+	}
+	if got := req.Header.Get("Authorization"); got != "" {
+		t.Errorf("Authorization = %q, want none for local servers", got)
+	}
+}
@@ -15,22 +15,39 @@ const (
 	InitialPrompts string = "Transcribe the speech. Output only the raw transcript text. Do not include timestamps, formatting, punctuation corrections, explanations, or answers to questions—just the plain spoken words exactly as heard."
 )
 
+// Info describes a transcription backend, it is recorded in the session manifest.
+type Info struct {
+	Mode     Mode
+	Model    string
+	Language string
+}
+
 type Client interface {
 	Transcribe(ctx context.Context, audioPath string) (io.ReadCloser, error)
 	ResetContext(ctx context.Context) error
+	Info() Info
 	Close() error
 }
 
-func NewClient(ctx context.Context, mode Mode, apiKey ...string) (Client, error) {
+type Options struct {
+	// APIKey is required by the gemini backend.
+	APIKey string
+	// Model is a ggml model path for whisper, or a model name for gemini.
+	Model string
+	// Language is the spoken language, e.g. "en", or "auto" to detect it.
+	Language string
+}
+
+func NewClient(ctx context.Context, mode Mode, opts Options) (Client, error) {
 	switch mode {
 	case WhisperMode:
-		return NewLocalClient()
+		return NewLocalClient(opts.Model, opts.Language)
 	case GeminiMode:
-		if len(apiKey) != 1 {
+		if opts.APIKey == "" {
 			return nil, errors.New("invalid credentials")
 		}
-		return NewGeminiClient(ctx, apiKey[0])
+		return NewGeminiClient(ctx, opts.APIKey, opts.Model, opts.Language)
 	default:
-		return nil, errors.New("invalid client mode, must be one of: local, google")
+		return nil, errors.New("invalid client mode, must be one of: whisper, gemini")
 	}
 }
@@ -10,11 +10,29 @@ import (
 )
 
 type GeminiClient struct {
-	client *genai.Client
-	model  string
+	client   *genai.Client
+	model    string
+	language string
 }
 
-func NewGeminiClient(ctx context.Context, apiKey string) (*GeminiClient, error) {
+const DefaultGeminiModel = "gemini-2.0-flash"
+
+// GeminiModels lists the models known to handle audio input, any other model name is accepted as well.
+var GeminiModels = []string{
+	"gemini-2.0-flash",
+	"gemini-2.0-flash-lite",
+	"gemini-2.5-flash",
+	"gemini-2.5-pro",
+}
+
+func NewGeminiClient(ctx context.Context, apiKey, model, language string) (*GeminiClient, error) {
+	if model == "" {
+		model = DefaultGeminiModel
+	}
+	if language == "" {
+		language = "auto"
+	}
+
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
 		APIKey:  apiKey,
 		Backend: genai.BackendGeminiAPI,
@@ -24,8 +42,9 @@ func NewGeminiClient(ctx context.Context, apiKey string) (*GeminiClient, error)
 	}
 
 	return &GeminiClient{
-		client: client,
-		model:  "gemini-2.0-flash",
+		client:   client,
+		model:    model,
+		language: language,
 	}, nil
 }
 
@@ -33,6 +52,14 @@ func (c *GeminiClient) ResetContext(_ context.Context) error {
 	return nil
 }
 
+func (c *GeminiClient) Info() Info {
+	return Info{
+		Mode:     GeminiMode,
+		Model:    c.model,
+		Language: c.language,
+	}
+}
+
 func (c *GeminiClient) Close() error {
 	return nil
 }
@@ -61,8 +88,9 @@ func (c *GeminiClient) Transcribe(ctx context.Context, audioPath string) (io.Rea
 		defer w.Close()
 		for chunk, chunkErr := range stream {
 			if chunkErr != nil {
-				c.writeToStream(ctx, w, normalizeError(chunkErr))
-				continue
+				// surface the failure to the reader instead of mixing it into the transcript
+				_ = w.CloseWithError(fmt.Errorf("gemini: %w", chunkErr))
+				return
 			}
 			c.writeToStream(ctx, w, chunk)
 		}
@@ -71,20 +99,6 @@ func (c *GeminiClient) Transcribe(ctx context.Context, audioPath string) (io.Rea
 	return r, nil
 }
 
-func normalizeError(chunkErr error) *genai.GenerateContentResponse {
-	return &genai.GenerateContentResponse{
-		Candidates: []*genai.Candidate{
-			{
-				Content: &genai.Content{
-					Parts: []*genai.Part{
-						genai.NewPartFromText(fmt.Sprintf("Error during transcription: %v", chunkErr)),
-					},
-				},
-			},
-		},
-	}
-}
-
 func (c *GeminiClient) newContentsFromAudio(audioPath string) ([]*genai.Content, error) {
 	audioBytes, err := os.ReadFile(audioPath)
 	if err != nil {
@@ -98,8 +112,13 @@ func (c *GeminiClient) newContentsFromAudio(audioPath string) ([]*genai.Content,
 		},
 	}
 
+	prompt := InitialPrompts
+	if c.language != "auto" {
+		prompt += fmt.Sprintf(" The speech is in the language with code %q.", c.language)
+	}
+
 	parts := []*genai.Part{
-		genai.NewPartFromText(InitialPrompts),
+		genai.NewPartFromText(prompt),
 		audioPart,
 	}
 
@@ -5,19 +5,29 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"regexp"
+	"strings"
 
 	"github.com/ggerganov/whisper.cpp/bindings/go/pkg/whisper"
 	"github.com/go-audio/wav"
 )
 
 type WhisperClient struct {
-	model whisper.Model
-	ctx   whisper.Context
+	modelPath string
+	language  string
+	model     whisper.Model
+	ctx       whisper.Context
 }
 
-func NewLocalClient() (*WhisperClient, error) {
-	model, err := whisper.New("models/ggml-medium.bin")
+const DefaultWhisperModel = "models/ggml-medium.bin"
+
+func NewLocalClient(modelPath, language string) (*WhisperClient, error) {
+	if modelPath == "" {
+		modelPath = DefaultWhisperModel
+	}
+
+	model, err := whisper.New(modelPath)
 	if err != nil {
 		return nil, err
 	}
@@ -27,15 +37,25 @@ func NewLocalClient() (*WhisperClient, error) {
 		return nil, err
 	}
 
-	fmt.Println("Whisper model loaded successfully!")
-	fmt.Print("\n\n")
+	// stdout is reserved for command output
+	_, _ = fmt.Fprint(os.Stderr, "Whisper model loaded successfully!\n\n\n")
 
 	return &WhisperClient{
-		model: model,
-		ctx:   modelContext,
+		modelPath: modelPath,
+		language:  language,
+		model:     model,
+		ctx:       modelContext,
 	}, nil
 }
 
+func (l *WhisperClient) Info() Info {
+	return Info{
+		Mode:     WhisperMode,
+		Model:    strings.TrimSuffix(filepath.Base(l.modelPath), ".bin"),
+		Language: l.ctx.Language(),
+	}
+}
+
 func (l *WhisperClient) Close() error {
 	return l.model.Close()
 }
@@ -48,6 +68,11 @@ func (l *WhisperClient) ResetContext(_ context.Context) error {
 
 	modelContext.SetTemperature(0.5)
 	modelContext.SetInitialPrompt(InitialPrompts)
+	if l.language != "" {
+		if err = modelContext.SetLanguage(l.language); err != nil {
+			return err
+		}
+	}
 
 	l.ctx = modelContext
 	return nil
@@ -0,0 +1,34 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/tuanta7/ekko/internal/session"
+)
+
+// startBookmark flags the current moment and asks for an optional note, the bookmark
+// is added once the note is confirmed.
+func (m *Model) startBookmark() tea.Cmd {
+	m.bookmarkAt = time.Now()
+	return m.startEditing("bookmark", "", "optional note, e.g. action item for Sam")
+}
+
+// addBookmark adds the bookmark to the session and the live transcript.
+func (m *Model) addBookmark(note string) {
+	b, err := m.app.AddBookmark(m.bookmarkAt, note)
+	if err != nil {
+		m.appendLine(lineError, fmt.Sprintf("failed to add bookmark: %v", err))
+	} else {
+		m.appendLine(lineBookmark, fmt.Sprintf("[%s] %s", session.FormatOffset(b.Offset()), bookmarkText(b.Note)))
+	}
+	m.refreshTranscript()
+}
+
+func bookmarkText(note string) string {
+	if note == "" {
+		return "🔖 bookmark"
+	}
+	return "🔖 " + note
+}
@@ -0,0 +1,464 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/reflow/wordwrap"
+	"github.com/tuanta7/ekko/internal/export"
+	"github.com/tuanta7/ekko/internal/search"
+	"github.com/tuanta7/ekko/internal/session"
+)
+
+// historyRows is how many sessions the history screen shows at once.
+const historyRows = 12
+
+type historyLoadedMsg struct {
+	Entries []session.Entry
+	Error   error
+}
+
+func (m *Model) loadHistory() tea.Cmd {
+	return func() tea.Msg {
+		entries, err := m.app.ListSessions()
+		return historyLoadedMsg{Entries: entries, Error: err}
+	}
+}
+
+type sessionOpenedMsg struct {
+	ID      string
+	Session *session.Session
+	Error   error
+}
+
+func (m *Model) openSession(id string) tea.Cmd {
+	return func() tea.Msg {
+		s, err := m.app.LoadSession(id)
+		return sessionOpenedMsg{ID: id, Session: s, Error: err}
+	}
+}
+
+// openViewer shows a saved transcript, scrolled to the chunk focus with the terms
+// highlighted when it is opened from the search results. esc goes back to the screen back.
+func (m *Model) openViewer(id string, back screen, focus int, terms []string) tea.Cmd {
+	m.errorMsg, m.infoMsg = "", ""
+	m.screen = screenViewer
+	m.viewerBack = back
+	m.viewingID = id
+	m.viewerFocus = focus
+	m.viewerTerms = terms
+	m.viewing = nil
+	m.viewer.SetContent("Loading…")
+	m.viewer.GotoTop()
+	return m.openSession(id)
+}
+
+// target returns the session the history actions apply to: the one in the viewer, or
+// the one under the cursor of the history screen.
+func (m *Model) target() (string, session.Manifest, bool) {
+	if m.screen == screenViewer {
+		if m.viewing == nil {
+			return "", session.Manifest{}, false
+		}
+		return m.viewingID, m.viewing.Manifest, true
+	}
+
+	entry, ok := m.selectedEntry()
+	return entry.Manifest.ID, entry.Manifest, ok
+}
+
+type historyActionMsg struct {
+	Info    string
+	Error   error
+	Deleted bool
+}
+
+// selectedEntry returns the session under the cursor of the history screen.
+func (m *Model) selectedEntry() (session.Entry, bool) {
+	if m.historyCursor < 0 || m.historyCursor >= len(m.history) {
+		return session.Entry{}, false
+	}
+	return m.history[m.historyCursor], true
+}
+
+func (m *Model) handleHistoryLoaded(msg historyLoadedMsg) (tea.Model, tea.Cmd) {
+	if msg.Error != nil {
+		m.errorMsg = fmt.Sprintf("Error: failed to list sessions: %v", msg.Error)
+		return m, nil
+	}
+
+	m.history = msg.Entries
+	m.historyCursor = min(m.historyCursor, max(len(m.history)-1, 0))
+	return m, nil
+}
+
+func (m *Model) handleSessionOpened(msg sessionOpenedMsg) (tea.Model, tea.Cmd) {
+	if msg.ID != m.viewingID || m.screen != screenViewer {
+		return m, nil // the viewer was closed in the meantime
+	}
+
+	if msg.Error != nil {
+		m.errorMsg = fmt.Sprintf("Error: %v", msg.Error)
+		m.screen = m.viewerBack
+		return m, nil
+	}
+
+	content, line := renderTranscript(msg.Session, m.viewer.Width-3, m.viewerFocus, m.viewerTerms)
+	m.viewing = msg.Session
+	m.viewer.SetContent(content)
+	if m.viewerFocus > 0 {
+		m.viewer.SetYOffset(line)
+	}
+	return m, nil
+}
+
+func (m *Model) handleHistoryAction(msg historyActionMsg) (tea.Model, tea.Cmd) {
+	m.errorMsg, m.infoMsg = "", msg.Info
+	if msg.Error != nil {
+		m.errorMsg = fmt.Sprintf("Error: %v", msg.Error)
+	}
+
+	if msg.Deleted && m.screen == screenViewer {
+		m.screen = m.viewerBack
+		m.viewing = nil
+	}
+
+	cmds := []tea.Cmd{m.loadHistory()}
+	switch m.screen {
+	case screenViewer:
+		cmds = append(cmds, m.openSession(m.viewingID))
+	case screenSearch:
+		cmds = append(cmds, m.runSearch(m.lastQuery))
+	}
+	return m, tea.Batch(cmds...)
+}
+
+func (m *Model) handleHistoryKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.confirmDelete {
+		return m, m.confirmDeletion(msg)
+	}
+
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "q":
+		m.screen = screenMenu
+		m.errorMsg, m.infoMsg = "", ""
+	case "up":
+		if m.historyCursor > 0 {
+			m.historyCursor--
+		}
+	case "down":
+		if m.historyCursor < len(m.history)-1 {
+			m.historyCursor++
+		}
+	case "enter":
+		entry, ok := m.selectedEntry()
+		if !ok {
+			return m, nil
+		}
+		return m, m.openViewer(entry.Manifest.ID, screenHistory, 0, nil)
+	default:
+		return m, m.startHistoryAction(msg.String())
+	}
+
+	return m, nil
+}
+
+func (m *Model) handleViewerKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.confirmDelete {
+		return m, m.confirmDeletion(msg)
+	}
+
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "q":
+		m.screen = m.viewerBack
+		m.viewing = nil
+		return m, nil
+	case "e", "r", "t", "d", "m":
+		return m, m.startHistoryAction(msg.String())
+	}
+
+	var cmd tea.Cmd
+	m.viewer, cmd = m.viewer.Update(msg)
+	return m, cmd
+}
+
+// startHistoryAction starts the action bound to key on the selected session.
+func (m *Model) startHistoryAction(key string) tea.Cmd {
+	_, manifest, ok := m.target()
+	if !ok {
+		return nil
+	}
+
+	switch key {
+	case "e":
+		formats := export.Formats()
+		return m.startEditing("export", formats[0], strings.Join(formats, ", "))
+	case "r":
+		return m.startEditing("rename", manifest.Title, "session title")
+	case "t":
+		return m.startEditing("tags", strings.Join(manifest.Tags, ", "), "comma separated, e.g. standup, team-a")
+	case "d":
+		m.confirmDelete = true
+	case "m":
+		if m.app.CanSummarize() {
+			return m.summarize()
+		}
+	}
+
+	return nil
+}
+
+func (m *Model) confirmDeletion(msg tea.KeyMsg) tea.Cmd {
+	m.confirmDelete = false
+	id, _, ok := m.target()
+	if msg.String() != "y" || !ok {
+		return nil
+	}
+
+	return func() tea.Msg {
+		if err := m.app.DeleteSession(id); err != nil {
+			return historyActionMsg{Error: err}
+		}
+		return historyActionMsg{Info: fmt.Sprintf("Deleted %s", id), Deleted: true}
+	}
+}
+
+// applyHistoryEdit completes the action started by startHistoryAction with the value entered.
+func (m *Model) applyHistoryEdit(target, value string) tea.Cmd {
+	id, _, ok := m.target()
+	if !ok {
+		return nil
+	}
+
+	return func() tea.Msg {
+		switch target {
+		case "export":
+			filename, err := m.app.ExportSession(id, strings.TrimSpace(value))
+			if err != nil {
+				return historyActionMsg{Error: err}
+			}
+			return historyActionMsg{Info: fmt.Sprintf("Exported to %s", filename)}
+		case "rename":
+			err := m.app.UpdateSession(id, func(s *session.Session) {
+				s.Manifest.Title = strings.TrimSpace(value)
+			})
+			return historyActionMsg{Info: "Session renamed", Error: err}
+		case "tags":
+			err := m.app.UpdateSession(id, func(s *session.Session) {
+				s.Manifest.Tags = session.ParseTags(value)
+			})
+			return historyActionMsg{Info: "Tags updated", Error: err}
+		default:
+			return nil
+		}
+	}
+}
+
+func (m *Model) historyView() string {
+	var b strings.Builder
+	b.WriteString(subtitleStyle.Render(" Session history"))
+	b.WriteString("\n")
+
+	var items strings.Builder
+	if len(m.history) == 0 {
+		items.WriteString(normalStyle.Render("No saved sessions yet."))
+		items.WriteString("\n")
+	}
+
+	start := max(0, m.historyCursor-historyRows+1)
+	end := min(len(m.history), start+historyRows)
+	for i := start; i < end; i++ {
+		mf := m.history[i].Manifest
+		row := fmt.Sprintf("%s  %8s  %-28s  %s",
+			mf.StartedAt.Format("2006-01-02 15:04"),
+			formatDuration(mf.Duration()),
+			truncate(titleOf(mf), 28),
+			orDash(mf.Backend))
+
+		if i == m.historyCursor {
+			items.WriteString(fmt.Sprintf(" %s %s\n", cursorStyle.Render("●"), selectedStyle.Render(row)))
+		} else {
+			items.WriteString(fmt.Sprintf("   %s\n", normalStyle.Render(row)))
+		}
+	}
+	if len(m.history) > historyRows {
+		items.WriteString(helpStyle.Render(fmt.Sprintf("   %d of %d", m.historyCursor+1, len(m.history))))
+		items.WriteString("\n")
+	}
+	b.WriteString(menuBoxStyle.Render(items.String()))
+
+	b.WriteString(m.historyFooter(fmt.Sprintf("%s navigate  %s open  %s export  %s rename  %s tags  %s%s delete  %s back",
+		helpKeyStyle.Render("↑↓"),
+		helpKeyStyle.Render("enter"),
+		helpKeyStyle.Render("e"),
+		helpKeyStyle.Render("r"),
+		helpKeyStyle.Render("t"),
+		m.summarizeHelp(),
+		helpKeyStyle.Render("d"),
+		helpKeyStyle.Render("esc"))))
+
+	return b.String()
+}
+
+func (m *Model) viewerView() string {
+	var b strings.Builder
+
+	if m.viewing != nil {
+		mf := m.viewing.Manifest
+		b.WriteString(subtitleStyle.Render(" " + titleOf(mf)))
+		b.WriteString("\n")
+		b.WriteString(normalStyle.Render(fmt.Sprintf(" %s  •  %s  •  %s (%s)",
+			mf.StartedAt.Format("2006-01-02 15:04"),
+			formatDuration(mf.Duration()),
+			orDash(mf.Backend),
+			orDash(mf.Model))))
+		if len(mf.Tags) > 0 {
+			b.WriteString(normalStyle.Render("  •  " + strings.Join(mf.Tags, ", ")))
+		}
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(transcriptBoxStyle.Render(transcriptTextStyle.Render(m.viewer.View())))
+	b.WriteString("\n")
+
+	b.WriteString(m.historyFooter(fmt.Sprintf("%s scroll  %s export  %s rename  %s tags  %s%s delete  %s back",
+		helpKeyStyle.Render("↑↓"),
+		helpKeyStyle.Render("e"),
+		helpKeyStyle.Render("r"),
+		helpKeyStyle.Render("t"),
+		m.summarizeHelp(),
+		helpKeyStyle.Render("d"),
+		helpKeyStyle.Render("esc"))))
+
+	return b.String()
+}
+
+// historyFooter renders the messages and the prompt of the history screens, or help
+// when there is no prompt.
+func (m *Model) historyFooter(help string) string {
+	var b strings.Builder
+
+	if m.errorMsg != "" {
+		b.WriteString("\n")
+		b.WriteString(errorStyle.Render(" ⚠ " + m.errorMsg + " "))
+		b.WriteString("\n")
+	}
+	if m.infoMsg != "" {
+		b.WriteString("\n")
+		b.WriteString(infoStyle.Render(" ℹ " + m.infoMsg + " "))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	switch {
+	case m.editing:
+		labels := map[string]string{"export": "Export format", "rename": "Title", "tags": "Tags"}
+		b.WriteString(fmt.Sprintf(" %s: %s\n", labels[m.editTarget], m.input.View()))
+		b.WriteString(helpStyle.Render(fmt.Sprintf("%s confirm  %s cancel",
+			helpKeyStyle.Render("enter"),
+			helpKeyStyle.Render("esc"))))
+	case m.confirmDelete:
+		_, manifest, _ := m.target()
+		b.WriteString(errorStyle.Render(fmt.Sprintf(" Delete %q and its audio? ", titleOf(manifest))))
+		b.WriteString("\n")
+		b.WriteString(helpStyle.Render(fmt.Sprintf("%s delete  %s cancel",
+			helpKeyStyle.Render("y"),
+			helpKeyStyle.Render("any key"))))
+	default:
+		b.WriteString(helpStyle.Render(help))
+	}
+
+	return b.String()
+}
+
+// renderTranscript formats a saved transcript for the viewer, below its summary, one
+// chunk per paragraph with its offset, the pauses and bookmarks in between. The chunk focus is highlighted,
+// with the terms marked, and its first line is returned. The paragraphs of a formatted
+// transcript are set apart by a blank line.
+func renderTranscript(s *session.Session, width, focus int, terms []string) (string, int) {
+	var lines []string
+	focusLine := 0
+	add := func(text string) {
+		lines = append(lines, strings.Split(wordwrap.String(text, width), "\n")...)
+	}
+
+	if s.Summary != nil {
+		lines = append(lines, strings.Split(renderSummary(s.Summary, width), "\n")...)
+	}
+
+	for _, item := range s.Timeline() {
+		if p := item.Pause; p != nil {
+			add(transcriptPendingStyle.Render(fmt.Sprintf("[%s] ⏸ paused for %s",
+				session.FormatOffset(p.Start()), formatDuration(p.Duration()))))
+			continue
+		}
+		if b := item.Bookmark; b != nil {
+			add(bookmarkStyle.Render(fmt.Sprintf("[%s] %s", session.FormatOffset(b.Offset()), bookmarkText(b.Note))))
+			continue
+		}
+
+		c := item.Chunk
+		stamp := helpKeyStyle.Render("[" + session.FormatOffset(c.Offset()) + "]")
+		if c.Seq == focus {
+			focusLine = len(lines)
+			stamp = cursorStyle.Render("▶ ") + stamp
+		}
+
+		if c.Error != "" {
+			add(stamp + " " + transcriptErrorStyle.Render("⚠ "+c.Error))
+			continue
+		}
+
+		text := strings.TrimSpace(c.Text)
+		if text == "" {
+			continue
+		}
+		if c.Seq == focus {
+			text = search.Highlight(text, terms, markMatch)
+		}
+		if c.Paragraph && len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		add(stamp + " " + text)
+	}
+
+	if len(lines) == 0 {
+		return "This session has no transcript.", 0
+	}
+	return strings.Join(lines, "\n"), focusLine
+}
+
+func titleOf(m session.Manifest) string {
+	if m.Title == "" {
+		return "Untitled"
+	}
+	return m.Title
+}
+
+func formatDuration(d time.Duration) string {
+	if d <= 0 {
+		return "-"
+	}
+	return d.Round(time.Second).String()
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -1,43 +1,165 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/tuanta7/ekko/internal/core"
 )
 
 type sessionEndMsg struct {
 	Timestamp time.Time
-	Filename  string
+	ID        string
 	Error     error
 }
 
+// sessionEnd stops the session in the background, the transcript events keep being
+// handled until the stream is closed.
 func (m *Model) sessionEnd() tea.Cmd {
-	filename, err := m.app.Stop()
 	return func() tea.Msg {
+		id, err := m.app.Stop()
 		return sessionEndMsg{
 			Timestamp: time.Now(),
-			Filename:  filename,
+			ID:        id,
 			Error:     err,
 		}
 	}
 }
 
-type transcriptChunkMsg struct {
-	Text string
+type transcriptEventMsg struct {
+	Event core.Event
 }
 
+// streamClosedMsg is sent when the stream of a session is closed, stream identifies the session.
+type streamClosedMsg struct {
+	stream <-chan core.Event
+}
+
+// ShutdownMsg asks the model to save the session in progress and quit, e.g. on SIGTERM.
+type ShutdownMsg struct{}
+
 func (m *Model) waitForTranscript() tea.Cmd {
+	stream := m.stream
 	return func() tea.Msg {
-		text, ok := <-m.stream
+		ev, ok := <-stream
 		if !ok {
-			_, _ = m.app.Stop()
-			return sessionEndMsg{
-				Timestamp: time.Now(),
-				Error:     fmt.Errorf("stream closed"),
+			return streamClosedMsg{stream: stream}
+		}
+		return transcriptEventMsg{Event: ev}
+	}
+}
+
+type housekeepingMsg struct {
+	Orphans     int
+	PrunedAudio int
+	Error       error
+}
+
+// housekeeping removes what previous sessions left behind.
+func (m *Model) housekeeping() tea.Cmd {
+	return func() tea.Msg {
+		orphans, orphansErr := m.app.RemoveOrphanedSessionDirs()
+		pruned, pruneErr := m.app.PruneAudio()
+		return housekeepingMsg{
+			Orphans:     orphans,
+			PrunedAudio: pruned,
+			Error:       errors.Join(orphansErr, pruneErr),
+		}
+	}
+}
+
+type interruptedFoundMsg struct {
+	Sessions   []core.InterruptedSession
+	Unreadable []core.UnreadableJournal
+	Error      error
+}
+
+func (m *Model) findInterrupted() tea.Cmd {
+	return func() tea.Msg {
+		sessions, unreadable, err := m.app.FindInterruptedSessions()
+		return interruptedFoundMsg{Sessions: sessions, Unreadable: unreadable, Error: err}
+	}
+}
+
+// unreadableJournals explains why interrupted sessions are not offered for recovery.
+func unreadableJournals(journals []core.UnreadableJournal) string {
+	var locked int
+	var errs []string
+	for _, j := range journals {
+		if j.Locked() {
+			locked++
+		} else {
+			errs = append(errs, j.Err.Error())
+		}
+	}
+
+	var msgs []string
+	if locked > 0 {
+		msgs = append(msgs, fmt.Sprintf("%d interrupted sessions are encrypted, a passphrase is needed to recover them (EKKO_PASSPHRASE)", locked))
+	}
+	if len(errs) > 0 {
+		msgs = append(msgs, fmt.Sprintf("%d interrupted sessions cannot be recovered, their journals are left as they are: %s",
+			len(errs), strings.Join(errs, "; ")))
+	}
+	return "Error: " + strings.Join(msgs, ". ")
+}
+
+type recoveryDoneMsg struct {
+	Info  string
+	Error error
+}
+
+func (m *Model) recoverInterrupted() tea.Cmd {
+	sessions := m.interrupted
+	return func() tea.Msg {
+		var errs []error
+		var saved []string
+		for _, is := range sessions {
+			id, err := m.app.RecoverSession(is.JournalPath)
+			if err != nil {
+				errs = append(errs, err)
 			}
+			if id != "" {
+				saved = append(saved, id)
+			}
+		}
+
+		msg := recoveryDoneMsg{Error: errors.Join(errs...)}
+		if len(saved) > 0 {
+			msg.Info = fmt.Sprintf("Recovered %d sessions: %s", len(saved), strings.Join(saved, ", "))
 		}
-		return transcriptChunkMsg{Text: text}
+		return msg
+	}
+}
+
+func (m *Model) discardInterrupted() tea.Cmd {
+	sessions := m.interrupted
+	return func() tea.Msg {
+		var errs []error
+		for _, is := range sessions {
+			if err := m.app.DiscardInterruptedSession(is.JournalPath); err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		return recoveryDoneMsg{
+			Info:  fmt.Sprintf("Discarded %d interrupted sessions", len(sessions)-len(errs)),
+			Error: errors.Join(errs...),
+		}
+	}
+}
+
+type settingSavedMsg struct {
+	Key   string
+	Error error
+}
+
+// saveSetting writes a setting changed in the UI back to the config file.
+func (m *Model) saveSetting(key string, value any) tea.Cmd {
+	return func() tea.Msg {
+		return settingSavedMsg{Key: key, Error: m.cfg.Save(key, value)}
 	}
 }
@@ -6,10 +6,13 @@ import (
 	"time"
 
 	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/muesli/reflow/wordwrap"
+	"github.com/tuanta7/ekko/internal/config"
 	"github.com/tuanta7/ekko/internal/core"
+	"github.com/tuanta7/ekko/internal/search"
+	"github.com/tuanta7/ekko/internal/session"
 	"github.com/tuanta7/ekko/pkg/logger"
 )
 
@@ -18,6 +21,10 @@ type screen int
 const (
 	screenMenu screen = iota
 	screenRecording
+	screenRecover
+	screenHistory
+	screenViewer
+	screenSearch
 )
 
 type Model struct {
@@ -25,70 +32,198 @@ type Model struct {
 	cursor          int
 	menuOptions     []string
 	chunkDuration   time.Duration
+	title           string
+	tags            string
 	errorMsg        string
+	infoMsg         string
 	sessionStopping bool
+	quitting        bool
 
-	spinner           spinner.Model
-	transcript        viewport.Model
-	transcriptContent string
-	chunkCount        int
-	sessionStart      time.Time
+	// the result of the last session, reported after the program exits
+	savedID        string
+	saveErr        error
+	pendingSummary string
+
+	editing    bool
+	editTarget string // what the input edits, a menu option or a history action
+	input      textinput.Model
+
+	interrupted []core.InterruptedSession
+
+	history       []session.Entry
+	historyCursor int
+	viewer        viewport.Model
+	viewing       *session.Session // the session open in the viewer
+	viewingID     string
+	viewerBack    screen   // where esc leaves the viewer for
+	viewerFocus   int      // the chunk to scroll to, 0 for the top
+	viewerTerms   []string // the words highlighted in the focused chunk
+	confirmDelete bool
+
+	query        textinput.Model
+	lastQuery    string
+	searching    bool
+	results      []search.Result
+	resultCursor int
+
+	spinner      spinner.Model
+	transcript   viewport.Model
+	lines        []transcriptLine
+	pendingText  string
+	following    bool // keep the newest chunks in view
+	findQuery    string
+	findMatches  []findMatch
+	findCurrent  int
+	bookmarkAt   time.Time // when the bookmark being noted was dropped
+	statusText   string
+	chunkCount   int
+	sessionStart time.Time
+	pausedAt     time.Time // zero while recording
 
 	app    *core.Application
-	stream <-chan string
+	cfg    *config.Config
+	stream <-chan core.Event
 	logger *logger.FileLogger
 }
 
-func NewModel(app *core.Application) *Model {
+func NewModel(app *core.Application, cfg *config.Config) *Model {
 	sp := spinner.New()
 	sp.Spinner = spinner.Dot
 
-	vp := viewport.New(100, 10)
+	vp := viewport.New(cfg.UI.TranscriptWidth, cfg.UI.TranscriptHeight)
 	vp.SetContent("")
 
+	viewer := viewport.New(cfg.UI.TranscriptWidth, cfg.UI.TranscriptHeight)
+
+	ti := textinput.New()
+	ti.CharLimit = 120
+	ti.Width = 40
+
+	query := textinput.New()
+	query.CharLimit = 120
+	query.Width = 40
+	query.Placeholder = "words to find"
+	query.Prompt = "/ "
+
+	var info string
+	if addr := cfg.Server.Listen; addr != "" && !strings.HasPrefix(addr, "unix:") {
+		info = fmt.Sprintf("Live captions at http://%s/", addr)
+	}
+
 	return &Model{
 		screen:        screenMenu,
-		menuOptions:   []string{"Start Session", "Chunk Duration", "Exit"},
+		infoMsg:       info,
+		menuOptions:   []string{"Start Session", "History", "Search", "Chunk Duration", "Title", "Tags", "Exit"},
 		spinner:       sp,
 		transcript:    vp,
+		viewer:        viewer,
+		input:         ti,
+		query:         query,
 		app:           app,
-		chunkDuration: 10 * time.Second,
+		cfg:           cfg,
+		chunkDuration: cfg.Recording.ChunkDuration,
 	}
 }
 
 func (m *Model) Init() tea.Cmd {
-	return nil
+	return tea.Batch(m.housekeeping(), m.findInterrupted())
 }
 
 func (m *Model) handleMenuSelection() (tea.Model, tea.Cmd) {
-	switch m.cursor {
-	case 0:
+	switch m.menuOptions[m.cursor] {
+	case "Start Session":
 		m.screen = screenRecording
-		m.transcriptContent = ""
+		m.lines = nil
+		m.pendingText = ""
+		m.following = true
+		m.findQuery, m.findMatches = "", nil
+		m.statusText = ""
 		m.transcript.SetContent("")
 		m.transcript.YOffset = 0
 		m.errorMsg = ""
+		m.infoMsg = ""
 		m.chunkCount = 0
 		m.sessionStart = time.Now()
+		m.pausedAt = time.Time{}
 
 		var err error
-		m.stream, err = m.app.Start(m.chunkDuration)
+		m.stream, err = m.app.Start(core.SessionOptions{
+			ChunkDuration: m.chunkDuration,
+			Title:         strings.TrimSpace(m.title),
+			Tags:          session.ParseTags(m.tags),
+		})
 		if err != nil {
-			return m, tea.Quit
+			m.screen = screenMenu
+			m.errorMsg = fmt.Sprintf("Error: %v", err)
+			return m, nil
 		}
 
 		return m, tea.Batch(m.spinner.Tick, m.waitForTranscript())
-	case 1:
+	case "History":
+		m.errorMsg = ""
+		m.infoMsg = ""
+		m.screen = screenHistory
+		return m, m.loadHistory()
+	case "Search":
+		return m, m.openSearch()
+	case "Chunk Duration":
 		// chunk duration, no action on selection
 		return m, nil
-	case len(m.menuOptions) - 1:
+	case "Title":
+		return m, m.startEditing("Title", m.title, "meeting title")
+	case "Tags":
+		return m, m.startEditing("Tags", m.tags, "comma separated, e.g. standup, team-a")
+	case "Exit":
 		return m, tea.Quit
 	default:
 		return m, nil
 	}
 }
 
+func (m *Model) startEditing(target, value, placeholder string) tea.Cmd {
+	m.editing = true
+	m.editTarget = target
+	m.input.SetValue(value)
+	m.input.Placeholder = placeholder
+	m.input.CursorEnd()
+	return m.input.Focus()
+}
+
+func (m *Model) handleEditKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		m.editing = false
+		m.input.Blur()
+
+		switch m.editTarget {
+		case "Title":
+			m.title = m.input.Value()
+		case "Tags":
+			m.tags = m.input.Value()
+		case "find":
+			m.setFindQuery(m.input.Value())
+		case "bookmark":
+			m.addBookmark(m.input.Value())
+		default:
+			return m, m.applyHistoryEdit(m.editTarget, m.input.Value())
+		}
+		return m, nil
+	case "esc":
+		m.editing = false
+		m.input.Blur()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
 func (m *Model) handleKeyEvent(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.editing {
+		return m.handleEditKey(msg)
+	}
+
 	switch m.screen {
 	case screenMenu:
 		switch msg.String() {
@@ -103,41 +238,109 @@ func (m *Model) handleKeyEvent(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.cursor++
 			}
 		case "left":
-			if m.cursor == 1 {
+			if m.menuOptions[m.cursor] == "Chunk Duration" {
 				if m.chunkDuration > time.Second {
 					m.chunkDuration -= time.Second
+					return m, m.saveSetting("recording.chunk_duration", m.chunkDuration)
 				}
 			}
 		case "right":
-			if m.cursor == 1 {
+			if m.menuOptions[m.cursor] == "Chunk Duration" {
 				if m.chunkDuration < 60*time.Second {
 					m.chunkDuration += time.Second
+					return m, m.saveSetting("recording.chunk_duration", m.chunkDuration)
 				}
 			}
 		case "enter":
 			return m.handleMenuSelection()
 		}
-	case screenRecording:
+	case screenRecover:
 		switch msg.String() {
 		case "ctrl+c", "q":
-			_, _ = m.app.Stop()
 			return m, tea.Quit
+		case "enter", "r":
+			return m, m.recoverInterrupted()
+		case "d":
+			return m, m.discardInterrupted()
+		case "esc":
+			m.screen = screenMenu
+			m.interrupted = nil
+		}
+	case screenHistory:
+		return m.handleHistoryKey(msg)
+	case screenViewer:
+		return m.handleViewerKey(msg)
+	case screenSearch:
+		return m.handleSearchKey(msg)
+	case screenRecording:
+		switch msg.String() {
+		case "ctrl+c", "q":
+			m.quitting = true
+			return m, m.stopSession()
 		case "s", "S":
+			return m, m.stopSession()
+		case "p", "P":
+			return m.togglePause()
+		case "b", "B":
 			if m.sessionStopping {
-				return m, nil // ignore spam
+				return m, nil
 			}
-			m.sessionStopping = true
-			return m, m.sessionEnd()
+			return m, m.startBookmark()
 		default:
-			var cmd tea.Cmd
-			m.transcript, cmd = m.transcript.Update(msg)
-			return m, cmd
+			return m.handleScrollKey(msg)
 		}
 	}
 
 	return m, nil
 }
 
+// togglePause pauses or resumes the recording, a resumed session is marked in the transcript.
+func (m *Model) togglePause() (tea.Model, tea.Cmd) {
+	if m.sessionStopping {
+		return m, nil
+	}
+
+	if m.pausedAt.IsZero() {
+		if err := m.app.Pause(); err != nil {
+			m.appendLine(lineError, err.Error())
+		} else {
+			m.pausedAt = time.Now()
+		}
+	} else {
+		if err := m.app.Resume(); err != nil {
+			m.appendLine(lineError, err.Error())
+		} else {
+			gap := time.Since(m.pausedAt).Round(time.Second)
+			m.appendLine(lineNote, fmt.Sprintf("⏸ paused for %s", gap))
+			m.pausedAt = time.Time{}
+		}
+	}
+
+	m.refreshTranscript()
+	return m, nil
+}
+
+// stopSession stops the session unless it is already stopping.
+func (m *Model) stopSession() tea.Cmd {
+	if m.sessionStopping {
+		return nil // ignore spam
+	}
+	m.sessionStopping = true
+	return m.sessionEnd()
+}
+
+// Saved returns the ID of the last saved session, or why it could not be saved. Like
+// Application.Stop, it returns both when the session was saved but a later step failed.
+func (m *Model) Saved() (string, error) {
+	return m.savedID, m.saveErr
+}
+
+// PendingSummary returns the ID of the session saved when quitting, which is still to
+// be summarized.
+func (m *Model) PendingSummary() string {
+	return m.pendingSummary
+}
+
 func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch mt := msg.(type) {
 	case tea.KeyMsg:
@@ -146,25 +349,133 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		var cmd tea.Cmd
 		m.spinner, cmd = m.spinner.Update(mt)
 		return m, cmd
-	case transcriptChunkMsg:
-		m.chunkCount++
-		m.transcriptContent += mt.Text + "\n"
-		wrapped := wordwrap.String(m.transcriptContent, m.transcript.Width-3)
-		m.transcript.SetContent(wrapped)
-		m.transcript.GotoBottom()
-		return m, m.waitForTranscript()
+	case transcriptEventMsg:
+		return m.handleTranscriptEvent(mt.Event)
+	case streamClosedMsg:
+		if mt.stream != m.stream || m.screen != screenRecording {
+			return m, nil // a previous session
+		}
+		// the session may have ended on its own, e.g. the source disappeared
+		return m, m.stopSession()
+	case ShutdownMsg:
+		if m.screen != screenRecording {
+			return m, tea.Quit
+		}
+		m.quitting = true
+		return m, m.stopSession()
+	case housekeepingMsg:
+		if mt.Error != nil {
+			m.errorMsg = fmt.Sprintf("Error: failed to clean up old sessions: %v", mt.Error)
+		}
+		var notes []string
+		if mt.Orphans > 0 {
+			notes = append(notes, fmt.Sprintf("Removed %d leftover session directories from a previous crash", mt.Orphans))
+		}
+		if mt.PrunedAudio > 0 {
+			notes = append(notes, fmt.Sprintf("Deleted %d recordings past their retention", mt.PrunedAudio))
+		}
+		if len(notes) > 0 {
+			m.infoMsg = strings.Join(notes, "; ")
+		}
+		return m, nil
+	case settingSavedMsg:
+		if mt.Error != nil {
+			m.errorMsg = fmt.Sprintf("Error: failed to save %s: %v", mt.Key, mt.Error)
+		}
+		return m, nil
+	case interruptedFoundMsg:
+		if mt.Error != nil {
+			m.errorMsg = fmt.Sprintf("Error: failed to look for interrupted sessions: %v", mt.Error)
+			return m, nil
+		}
+		if len(mt.Unreadable) > 0 {
+			m.errorMsg = unreadableJournals(mt.Unreadable)
+		}
+		if len(mt.Sessions) > 0 && m.screen == screenMenu {
+			m.interrupted = mt.Sessions
+			m.screen = screenRecover
+		}
+		return m, nil
+	case recoveryDoneMsg:
+		m.screen = screenMenu
+		m.interrupted = nil
+		if mt.Error != nil {
+			m.errorMsg = fmt.Sprintf("Error: %v", mt.Error)
+		}
+		if mt.Info != "" {
+			m.infoMsg = mt.Info
+		}
+		return m, nil
+	case historyLoadedMsg:
+		return m.handleHistoryLoaded(mt)
+	case sessionOpenedMsg:
+		return m.handleSessionOpened(mt)
+	case searchResultsMsg:
+		return m.handleSearchResults(mt)
+	case historyActionMsg:
+		return m.handleHistoryAction(mt)
 	case sessionEndMsg:
 		m.screen = screenMenu
 		m.sessionStopping = false // reset guard
-		if mt.Error != nil {
+		m.savedID, m.saveErr = mt.ID, mt.Error
+		switch {
+		case mt.ID == "":
 			m.errorMsg = fmt.Sprintf("Error: %v", mt.Error)
+		case mt.Error != nil:
+			// saved, but the journal removal, the exports or the indexing failed
+			m.errorMsg = fmt.Sprintf("Saved session %s, but: %v", mt.ID, mt.Error)
+		case m.app.CanSummarize() && m.quitting:
+			// summarized once the terminal is restored, see PendingSummary
+			m.pendingSummary = mt.ID
+		case m.app.CanSummarize():
+			m.infoMsg = fmt.Sprintf("Saved session %s, summarizing…", mt.ID)
+			return m, m.summarizeSaved(mt.ID)
+		default:
+			m.infoMsg = fmt.Sprintf("Saved session %s", mt.ID)
+		}
+		if m.quitting {
+			return m, tea.Quit
 		}
 		return m, nil
+	case summaryDoneMsg:
+		return m.handleSummaryDone(mt)
 	default:
+		if m.editing {
+			// keep the text input cursor blinking
+			var cmd tea.Cmd
+			m.input, cmd = m.input.Update(msg)
+			return m, cmd
+		}
 		return m, nil
 	}
 }
 
+func (m *Model) handleTranscriptEvent(ev core.Event) (tea.Model, tea.Cmd) {
+	switch ev.Type {
+	case core.EventPartial:
+		m.pendingText += ev.Text
+	case core.EventChunk:
+		m.chunkCount++
+		m.pendingText = ""
+		if ev.Text != "" {
+			if ev.Chunk != nil && ev.Chunk.Paragraph && len(m.lines) > 0 {
+				m.appendLine(lineText, "")
+			}
+			m.appendLine(lineText, ev.Text)
+		}
+	case core.EventError:
+		m.pendingText = ""
+		m.appendLine(lineError, ev.Err.Error())
+	case core.EventStatus:
+		m.statusText = ev.Text
+	case core.EventSessionEnd:
+		// the stream is about to be closed, see streamClosedMsg
+	}
+
+	m.refreshTranscript()
+	return m, m.waitForTranscript()
+}
+
 func (m *Model) View() string {
 	var b strings.Builder
 
@@ -182,10 +493,28 @@ func (m *Model) View() string {
 			switch choice {
 			case "Start Session":
 				icon = "▶"
+			case "History":
+				icon = "☰"
+			case "Search":
+				icon = "⌕"
 			case "Chunk Duration":
 				icon = "⏱"
 				durVal := durationValueStyle.Render(fmt.Sprintf("%ds", int(m.chunkDuration.Seconds())))
 				label = fmt.Sprintf("Chunk Duration: %s  ◀ ▶", durVal)
+			case "Title", "Tags":
+				icon = "✎"
+				value := m.title
+				if choice == "Tags" {
+					value = m.tags
+				}
+				switch {
+				case m.editing && m.cursor == i:
+					label = fmt.Sprintf("%s: %s", choice, m.input.View())
+				case strings.TrimSpace(value) == "":
+					label = fmt.Sprintf("%s: %s", choice, "—")
+				default:
+					label = fmt.Sprintf("%s: %s", choice, durationValueStyle.Render(value))
+				}
 			case "Exit":
 				icon = "✕"
 			}
@@ -205,30 +534,115 @@ func (m *Model) View() string {
 			b.WriteString("\n")
 		}
 
+		if m.infoMsg != "" {
+			b.WriteString("\n")
+			b.WriteString(infoStyle.Render(" ℹ " + m.infoMsg + " "))
+			b.WriteString("\n")
+		}
+
 		b.WriteString("\n")
 		help := fmt.Sprintf("%s navigate  %s adjust  %s select  %s quit",
 			helpKeyStyle.Render("↑↓"),
 			helpKeyStyle.Render("←→"),
 			helpKeyStyle.Render("enter"),
 			helpKeyStyle.Render("q"))
+		if m.editing {
+			help = fmt.Sprintf("%s save  %s cancel",
+				helpKeyStyle.Render("enter"),
+				helpKeyStyle.Render("esc"))
+		}
+		b.WriteString(helpStyle.Render(help))
+
+	case screenRecover:
+		b.WriteString(subtitleStyle.Render(" Interrupted sessions found"))
+		b.WriteString("\n")
+
+		var items strings.Builder
+		items.WriteString(normalStyle.Render("These sessions were not saved, probably because ekko was closed unexpectedly."))
+		items.WriteString("\n\n")
+		for _, is := range m.interrupted {
+			items.WriteString(fmt.Sprintf(" • %s  %s  %s\n",
+				selectedStyle.Render(titleOf(is.Manifest)),
+				normalStyle.Render(is.Manifest.StartedAt.Format("2006-01-02 15:04")),
+				durationValueStyle.Render(fmt.Sprintf("%d chunks", is.Chunks))))
+		}
+		b.WriteString(menuBoxStyle.Render(items.String()))
+
+		b.WriteString("\n")
+		help := fmt.Sprintf("%s recover & save  %s discard  %s later  %s quit",
+			helpKeyStyle.Render("enter"),
+			helpKeyStyle.Render("d"),
+			helpKeyStyle.Render("esc"),
+			helpKeyStyle.Render("q"))
 		b.WriteString(helpStyle.Render(help))
 
+	case screenHistory:
+		b.WriteString(m.historyView())
+
+	case screenViewer:
+		b.WriteString(m.viewerView())
+
+	case screenSearch:
+		b.WriteString(m.searchView())
+
 	case screenRecording:
 		elapsed := time.Since(m.sessionStart).Round(time.Second)
 		recDot := recordingDotStyle.Render("●")
-		status := fmt.Sprintf("%s %s  Recording  •  %s elapsed  •  %d chunks",
+		state := "Recording"
+		switch {
+		case m.sessionStopping:
+			state = "Saving, transcribing the last chunks"
+		case !m.pausedAt.IsZero():
+			recDot = pausedDotStyle.Render("⏸")
+			state = fmt.Sprintf("Paused for %s", time.Since(m.pausedAt).Round(time.Second))
+		}
+		status := fmt.Sprintf("%s %s  %s  •  %s elapsed  •  %d chunks",
 			m.spinner.View(),
 			recDot,
+			state,
 			elapsed.String(),
 			m.chunkCount)
+		if !m.following {
+			status += "  •  scrolled back"
+		}
+		if find := m.findStatus(); find != "" {
+			status += "  •  " + find
+		}
 		b.WriteString(statusStyle.Render(status))
 		b.WriteString("\n")
+		if m.statusText != "" {
+			b.WriteString(subtitleStyle.Render(m.statusText))
+			b.WriteString("\n")
+		}
 		b.WriteString(transcriptBoxStyle.Render(transcriptTextStyle.Render(m.transcript.View())))
 		b.WriteString("\n\n")
 
+		if m.editing {
+			label, action := "Find", "find"
+			if m.editTarget == "bookmark" {
+				label, action = "Bookmark at "+session.FormatOffset(m.bookmarkAt.Sub(m.sessionStart)), "add"
+			}
+			b.WriteString(fmt.Sprintf(" %s: %s\n", label, m.input.View()))
+			b.WriteString(helpStyle.Render(fmt.Sprintf("%s %s  %s cancel",
+				helpKeyStyle.Render("enter"),
+				action,
+				helpKeyStyle.Render("esc"))))
+			break
+		}
+
 		// Help
-		help := fmt.Sprintf("%s scroll  %s stop & save  %s quit",
+		pause := "pause"
+		if !m.pausedAt.IsZero() {
+			pause = "resume"
+		}
+		help := fmt.Sprintf("%s scroll  %s find  %s next/prev  %s live  %s bookmark  %s %s  %s stop & save  %s save & quit",
 			helpKeyStyle.Render("↑↓"),
+			helpKeyStyle.Render("/"),
+			helpKeyStyle.Render("n/N"),
+			helpKeyStyle.Render("G"),
+			helpKeyStyle.Render("b"),
+			helpKeyStyle.Render("p"),
+			pause,
 			helpKeyStyle.Render("s"),
 			helpKeyStyle.Render("q"))
 		b.WriteString(helpStyle.Render(help))
@@ -0,0 +1,188 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/reflow/wordwrap"
+)
+
+type lineKind int
+
+const (
+	lineText lineKind = iota
+	lineError
+	lineNote // e.g. the end of a pause
+	lineBookmark
+)
+
+// transcriptLine is a line of the live transcript, styled when rendered so the search
+// highlighting applies to the text only.
+type transcriptLine struct {
+	kind lineKind
+	text string
+}
+
+// findMatch locates an occurrence of the searched text in the live transcript.
+type findMatch struct {
+	line       int
+	start, end int // byte offsets in the line text
+}
+
+func (m *Model) appendLine(kind lineKind, text string) {
+	m.lines = append(m.lines, transcriptLine{kind: kind, text: text})
+}
+
+// handleScrollKey scrolls the live transcript. Scrolling up stops following the new
+// chunks until the view is scrolled back to the bottom.
+func (m *Model) handleScrollKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "/":
+		return m, m.startEditing("find", m.findQuery, "text to find")
+	case "n":
+		m.jumpToMatch(m.findCurrent + 1)
+		return m, nil
+	case "N":
+		m.jumpToMatch(m.findCurrent - 1)
+		return m, nil
+	case "esc":
+		m.setFindQuery("")
+		return m, nil
+	case "G", "end":
+		m.following = true
+		m.transcript.GotoBottom()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.transcript, cmd = m.transcript.Update(msg)
+	m.following = m.transcript.AtBottom()
+	return m, cmd
+}
+
+// setFindQuery searches the live transcript and shows the last match, the most recent.
+func (m *Model) setFindQuery(query string) {
+	m.findQuery = strings.TrimSpace(query)
+	m.findMatches = m.findAll()
+	m.findCurrent = len(m.findMatches) - 1
+	if len(m.findMatches) == 0 {
+		m.refreshTranscript()
+		return
+	}
+	m.jumpToMatch(m.findCurrent)
+}
+
+// jumpToMatch scrolls to the i-th match, wrapping around at both ends.
+func (m *Model) jumpToMatch(i int) {
+	n := len(m.findMatches)
+	if n == 0 {
+		return
+	}
+
+	m.findCurrent = (i%n + n) % n
+	m.following = false
+	line := m.refreshTranscript()
+	m.transcript.SetYOffset(max(0, line-m.transcript.Height/2))
+}
+
+func (m *Model) findAll() []findMatch {
+	if m.findQuery == "" {
+		return nil
+	}
+
+	re := regexp.MustCompile("(?i)" + regexp.QuoteMeta(m.findQuery))
+	var matches []findMatch
+	for i, l := range m.lines {
+		for _, loc := range re.FindAllStringIndex(l.text, -1) {
+			matches = append(matches, findMatch{line: i, start: loc[0], end: loc[1]})
+		}
+	}
+	return matches
+}
+
+// refreshTranscript renders the live transcript with the matches highlighted, and
+// returns the wrapped line holding the current match. The view stays at the bottom
+// while following.
+func (m *Model) refreshTranscript() int {
+	if m.findQuery != "" {
+		// new chunks may hold new matches, the current one keeps its position
+		m.findMatches = m.findAll()
+		m.findCurrent = min(max(m.findCurrent, 0), len(m.findMatches)-1)
+	}
+
+	width := m.transcript.Width - 3
+	var b strings.Builder
+	currentLine, wrapped := 0, 0
+	next := 0 // first match of the line being rendered
+	for i, l := range m.lines {
+		text := l.text
+		end := next
+		for end < len(m.findMatches) && m.findMatches[end].line == i {
+			end++
+		}
+		if end > next {
+			if m.findCurrent >= next && m.findCurrent < end {
+				cur := m.findMatches[m.findCurrent]
+				currentLine = wrapped + strings.Count(wordwrap.String(text[:cur.start], width), "\n")
+			}
+			text = m.highlightMatches(text, next, end)
+			next = end
+		}
+
+		switch l.kind {
+		case lineError:
+			text = transcriptErrorStyle.Render("⚠ " + text)
+		case lineNote:
+			text = transcriptPendingStyle.Render(text)
+		case lineBookmark:
+			text = bookmarkStyle.Render(text)
+		}
+
+		text = wordwrap.String(text, width)
+		wrapped += strings.Count(text, "\n") + 1
+		b.WriteString(text)
+		b.WriteString("\n")
+	}
+
+	if m.pendingText != "" {
+		b.WriteString(wordwrap.String(transcriptPendingStyle.Render(m.pendingText), width))
+	}
+
+	m.transcript.SetContent(b.String())
+	if m.following {
+		m.transcript.GotoBottom()
+	}
+	return currentLine
+}
+
+// highlightMatches marks the matches from..to, all in the same line, the current one
+// standing out.
+func (m *Model) highlightMatches(text string, from, to int) string {
+	var b strings.Builder
+	last := 0
+	for i := from; i < to; i++ {
+		match := m.findMatches[i]
+		b.WriteString(text[last:match.start])
+		style := searchMatchStyle
+		if i == m.findCurrent {
+			style = currentMatchStyle
+		}
+		b.WriteString(style.Render(text[match.start:match.end]))
+		last = match.end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// findStatus describes the search for the status line, e.g. "budget 2/5".
+func (m *Model) findStatus() string {
+	if m.findQuery == "" {
+		return ""
+	}
+	if len(m.findMatches) == 0 {
+		return fmt.Sprintf("%q not found", m.findQuery)
+	}
+	return fmt.Sprintf("%q %d/%d", m.findQuery, m.findCurrent+1, len(m.findMatches))
+}
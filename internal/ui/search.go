@@ -0,0 +1,153 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/tuanta7/ekko/internal/search"
+	"github.com/tuanta7/ekko/internal/session"
+)
+
+const (
+	// searchLimit is how many results a search returns.
+	searchLimit = 50
+	// searchRows is how many results the search screen shows at once.
+	searchRows = 8
+)
+
+type searchResultsMsg struct {
+	Query   string
+	Results []search.Result
+	Error   error
+}
+
+func (m *Model) runSearch(query string) tea.Cmd {
+	return func() tea.Msg {
+		results, err := m.app.Search(query, searchLimit)
+		return searchResultsMsg{Query: query, Results: results, Error: err}
+	}
+}
+
+func (m *Model) openSearch() tea.Cmd {
+	m.errorMsg, m.infoMsg = "", ""
+	m.screen = screenSearch
+	m.query.CursorEnd()
+	return m.query.Focus()
+}
+
+func (m *Model) handleSearchResults(msg searchResultsMsg) (tea.Model, tea.Cmd) {
+	if msg.Query != m.lastQuery {
+		return m, nil // superseded by a later search
+	}
+
+	m.searching = false
+	m.errorMsg = ""
+	if msg.Error != nil && !errors.Is(msg.Error, search.ErrEmptyQuery) {
+		m.errorMsg = fmt.Sprintf("Error: %v", msg.Error)
+	}
+
+	m.results = msg.Results
+	m.resultCursor = min(m.resultCursor, max(len(m.results)-1, 0))
+	return m, nil
+}
+
+func (m *Model) handleSearchKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		m.query.Blur()
+		m.screen = screenMenu
+		m.errorMsg, m.infoMsg = "", ""
+		return m, nil
+	case "up":
+		if m.resultCursor > 0 {
+			m.resultCursor--
+		}
+		return m, nil
+	case "down":
+		if m.resultCursor < len(m.results)-1 {
+			m.resultCursor++
+		}
+		return m, nil
+	case "enter":
+		// a new query is searched first, enter again opens the selected result
+		if query := m.query.Value(); query != m.lastQuery || m.searching {
+			m.lastQuery = query
+			m.searching = true
+			m.resultCursor = 0
+			return m, m.runSearch(query)
+		}
+
+		if m.resultCursor >= len(m.results) {
+			return m, nil
+		}
+		r := m.results[m.resultCursor]
+		return m, m.openViewer(r.ID, screenSearch, r.Seq, r.Terms)
+	}
+
+	var cmd tea.Cmd
+	m.query, cmd = m.query.Update(msg)
+	return m, cmd
+}
+
+func (m *Model) searchView() string {
+	var b strings.Builder
+	b.WriteString(subtitleStyle.Render(" Search transcripts"))
+	b.WriteString("\n")
+	b.WriteString(fmt.Sprintf(" %s\n\n", m.query.View()))
+
+	var items strings.Builder
+	switch {
+	case m.searching:
+		items.WriteString(normalStyle.Render("Searching…"))
+		items.WriteString("\n")
+	case m.lastQuery != "" && len(m.results) == 0:
+		items.WriteString(normalStyle.Render("No matches."))
+		items.WriteString("\n")
+	case m.lastQuery == "":
+		items.WriteString(normalStyle.Render("Type words to find, then press enter."))
+		items.WriteString("\n")
+	}
+
+	width := m.viewer.Width - 6
+	start := max(0, m.resultCursor-searchRows+1)
+	end := min(len(m.results), start+searchRows)
+	for i := start; i < end; i++ {
+		r := m.results[i]
+		title := r.Title
+		if title == "" {
+			title = "Untitled"
+		}
+		header := fmt.Sprintf("%s  [%s]  %s",
+			r.StartedAt.Format("2006-01-02 15:04"),
+			session.FormatOffset(r.Offset),
+			truncate(title, 28))
+		snippet := search.Highlight(search.Snippet(r.Text, r.Terms, width), r.Terms, markMatch)
+
+		if i == m.resultCursor {
+			items.WriteString(fmt.Sprintf(" %s %s\n", cursorStyle.Render("●"), selectedStyle.Render(header)))
+		} else {
+			items.WriteString(fmt.Sprintf("   %s\n", normalStyle.Render(header)))
+		}
+		items.WriteString(fmt.Sprintf("     %s\n", snippet))
+	}
+	if len(m.results) > searchRows {
+		items.WriteString(helpStyle.Render(fmt.Sprintf("   %d of %d", m.resultCursor+1, len(m.results))))
+		items.WriteString("\n")
+	}
+	b.WriteString(menuBoxStyle.Render(items.String()))
+
+	b.WriteString(m.historyFooter(fmt.Sprintf("%s search / open  %s navigate  %s back",
+		helpKeyStyle.Render("enter"),
+		helpKeyStyle.Render("↑↓"),
+		helpKeyStyle.Render("esc"))))
+
+	return b.String()
+}
+
+func markMatch(word string) string {
+	return searchMatchStyle.Render(word)
+}
@@ -54,6 +54,10 @@ var (
 				Foreground(lipgloss.Color("#FF6B6B")).
 				Bold(true)
 
+	pausedDotStyle = lipgloss.NewStyle().
+			Foreground(textMuted).
+			Bold(true)
+
 	transcriptBoxStyle = lipgloss.NewStyle().
 				Foreground(textPrimary).
 				BorderForeground(accentBlue).
@@ -68,6 +72,13 @@ var (
 	transcriptTextStyle = lipgloss.NewStyle().
 				Foreground(textPrimary)
 
+	transcriptPendingStyle = lipgloss.NewStyle().
+				Foreground(textMuted)
+
+	transcriptErrorStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#FF6B6B")).
+				Italic(true)
+
 	errorStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#FF6B6B")).
 			Bold(true).
@@ -75,7 +86,31 @@ var (
 			Border(lipgloss.RoundedBorder()).
 			BorderForeground(lipgloss.Color("#FF6B6B"))
 
+	infoStyle = lipgloss.NewStyle().
+			Foreground(accentGreen).
+			Padding(0, 1).
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(accentGreen)
+
 	durationValueStyle = lipgloss.NewStyle().
 				Foreground(accentGreen).
 				Bold(true)
+
+	searchMatchStyle = lipgloss.NewStyle().
+				Foreground(accentGreen).
+				Bold(true).
+				Underline(true)
+
+	summaryHeadingStyle = lipgloss.NewStyle().
+				Foreground(accentGreen).
+				Bold(true)
+
+	bookmarkStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FFD166")).
+			Bold(true)
+
+	currentMatchStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#000000")).
+				Background(accentGreen).
+				Bold(true)
 )
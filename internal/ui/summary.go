@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/reflow/wordwrap"
+	"github.com/tuanta7/ekko/internal/session"
+)
+
+// summaryDoneMsg is sent when the session saved by the recording screen is summarized.
+type summaryDoneMsg struct {
+	ID    string
+	Error error
+}
+
+func (m *Model) summarizeSaved(id string) tea.Cmd {
+	return func() tea.Msg {
+		_, err := m.app.Summarize(context.Background(), id)
+		return summaryDoneMsg{ID: id, Error: err}
+	}
+}
+
+// summarize writes the summary of the session the history actions apply to again.
+func (m *Model) summarize() tea.Cmd {
+	id, _, ok := m.target()
+	if !ok {
+		return nil
+	}
+
+	m.errorMsg, m.infoMsg = "", "Summarizing…"
+	return func() tea.Msg {
+		if _, err := m.app.Summarize(context.Background(), id); err != nil {
+			return historyActionMsg{Error: err}
+		}
+		return historyActionMsg{Info: "Summary updated"}
+	}
+}
+
+// handleSummaryDone shows the summarized session, unless another screen was opened
+// while waiting for the summary.
+func (m *Model) handleSummaryDone(msg summaryDoneMsg) (tea.Model, tea.Cmd) {
+	if msg.Error != nil {
+		m.infoMsg = ""
+		m.errorMsg = fmt.Sprintf("Failed to summarize session %s: %v", msg.ID, msg.Error)
+		return m, nil
+	}
+
+	if m.screen != screenMenu {
+		m.infoMsg = fmt.Sprintf("Summarized session %s", msg.ID)
+		return m, nil
+	}
+	return m, m.openViewer(msg.ID, screenMenu, 0, nil)
+}
+
+// summarizeHelp is the help of the summarize action, empty when no provider is configured.
+func (m *Model) summarizeHelp() string {
+	if !m.app.CanSummarize() {
+		return ""
+	}
+	return helpKeyStyle.Render("m") + " summarize  "
+}
+
+// renderSummary formats the summary shown above the transcript in the viewer.
+func renderSummary(sum *session.Summary, width int) string {
+	var b strings.Builder
+	b.WriteString(summaryHeadingStyle.Render("Summary"))
+	b.WriteString("\n")
+	b.WriteString(wordwrap.String(sum.Text, width))
+	b.WriteString("\n")
+
+	if len(sum.Decisions) > 0 {
+		b.WriteString("\n")
+		b.WriteString(summaryHeadingStyle.Render("Decisions"))
+		b.WriteString("\n")
+		for _, d := range sum.Decisions {
+			b.WriteString(wordwrap.String("• "+d, width))
+			b.WriteString("\n")
+		}
+	}
+
+	if len(sum.ActionItems) > 0 {
+		b.WriteString("\n")
+		b.WriteString(summaryHeadingStyle.Render("Action items"))
+		b.WriteString("\n")
+		for _, item := range sum.ActionItems {
+			line := "☐ " + item.Task
+			if item.Owner != "" {
+				line += " " + helpKeyStyle.Render("@"+item.Owner)
+			}
+			b.WriteString(wordwrap.String(line, width))
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString(helpStyle.Render(fmt.Sprintf("by %s", orDash(sum.Model))))
+	b.WriteString("\n")
+	return b.String()
+}
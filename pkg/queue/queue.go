@@ -15,6 +15,9 @@ var (
 type Message struct {
 	Timestamp time.Time
 	FileName  string
+	Sequence  uint32
+	// Offset is the position of the recording relative to the session start.
+	Offset time.Duration
 }
 
 type RecordQueue struct {
@@ -0,0 +1,41 @@
+// Package xdg resolves base directories following the XDG Base Directory Specification.
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// DataHome returns $XDG_DATA_HOME, defaulting to ~/.local/share.
+func DataHome() string {
+	return lookup("XDG_DATA_HOME", ".local", "share")
+}
+
+// ConfigHome returns $XDG_CONFIG_HOME, defaulting to ~/.config.
+func ConfigHome() string {
+	return lookup("XDG_CONFIG_HOME", ".config")
+}
+
+// StateHome returns $XDG_STATE_HOME, defaulting to ~/.local/state.
+func StateHome() string {
+	return lookup("XDG_STATE_HOME", ".local", "state")
+}
+
+// CacheHome returns $XDG_CACHE_HOME, defaulting to ~/.cache.
+func CacheHome() string {
+	return lookup("XDG_CACHE_HOME", ".cache")
+}
+
+func lookup(env string, fallback ...string) string {
+	// relative paths are invalid according to the specification and must be ignored
+	if dir := os.Getenv(env); filepath.IsAbs(dir) {
+		return dir
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(append([]string{os.TempDir()}, fallback...)...)
+	}
+
+	return filepath.Join(append([]string{home}, fallback...)...)
+}